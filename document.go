@@ -5,13 +5,14 @@
 package rst
 
 import (
-	"fmt"
+	"strings"
 
 	"github.com/demizer/go-rst/parse"
 )
 
 type Document struct {
 	name string
+	text string
 	*parse.Tree
 }
 
@@ -21,11 +22,92 @@ func New(name string) *Document {
 	}
 }
 
-func (d *Document) Parse(text string) (*Document, error) {
-	tree, err := parse.Parse(d.name, text)
-	if err != nil {
-		return nil, nil
+// Parse parses text into the Document. opts are the options of parse.Parse,
+// such as parse.WithFrontMatterComment. The system messages generated while
+// parsing are part of the parse tree and do not cause an error.
+func (d *Document) Parse(text string,
+	opts ...parse.ParseOption) (*Document, error) {
+	d.text = text
+	d.Tree, _ = parse.Parse(d.name, text, opts...)
+	return d, nil
+}
+
+// Source returns the text the Document was parsed from.
+func (d *Document) Source() string {
+	return d.text
+}
+
+// Annotation is a "key: value" pair found on the first line of a comment,
+// such as:
+//
+//	.. owner: platform-team
+type Annotation struct {
+	Key   string
+	Value string
+
+	// Line and StartPosition are the location of the annotation key in the
+	// input.
+	parse.Line
+	parse.StartPosition
+
+	// Body contains the lines of the comment following the annotation.
+	Body string
+}
+
+// Annotations returns the annotations found in the comments of the document,
+// in document order. If prefixes are given, only annotations with a key
+// beginning with one of the prefixes are returned. Annotations are read from
+// the parsed comments, parsing is not affected.
+func (d *Document) Annotations(prefixes ...string) (a []Annotation) {
+	if d.Tree == nil {
+		return
+	}
+	comment := func(c *parse.CommentNode) {
+		n, ok := annotation(c)
+		if !ok {
+			return
+		}
+		for _, p := range prefixes {
+			if strings.HasPrefix(n.Key, p) {
+				a = append(a, n)
+				return
+			}
+		}
+		if len(prefixes) == 0 {
+			a = append(a, n)
+		}
 	}
-	fmt.Printf("%#v\n", tree)
-	return nil, nil
+	(&textWalker{comment: comment}).nodes(d.Nodes)
+	return
+}
+
+// annotation returns the annotation in the first line of the comment c. The
+// key must be a single word of letters, digits, '-', '_', or '.', followed by
+// a colon, a space, and a value.
+func annotation(c *parse.CommentNode) (a Annotation, ok bool) {
+	lines := strings.SplitN(c.Text, "\n", 2)
+	kv := strings.SplitN(lines[0], ": ", 2)
+	if len(kv) != 2 || kv[0] == "" || strings.TrimSpace(kv[1]) == "" {
+		return
+	}
+	for _, r := range kv[0] {
+		if !isAnnotationKeyRune(r) {
+			return
+		}
+	}
+	a = Annotation{
+		Key:           kv[0],
+		Value:         strings.TrimSpace(kv[1]),
+		Line:          c.Line,
+		StartPosition: c.StartPosition,
+	}
+	if len(lines) > 1 {
+		a.Body = lines[1]
+	}
+	return a, true
+}
+
+func isAnnotationKeyRune(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' ||
+		r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.'
 }
@@ -3,3 +3,480 @@
 // MIT Licensed. See LICENSE for details.
 
 package rst
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/demizer/go-rst/parse"
+	"github.com/demizer/go-rst/rsttest"
+)
+
+var annotationText = `.. owner: platform-team
+   Contact #platform
+   on chat.
+
+.. owner is nobody
+
+.. http://example.com
+
+Paragraph.
+
+    .. review-by: 2025-01-01
+`
+
+func TestDocumentAnnotations(t *testing.T) {
+	doc, err := New("annotations").Parse(annotationText)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := []Annotation{
+		{Key: "owner", Value: "platform-team", Line: 1, StartPosition: 4,
+			Body: "Contact #platform\non chat."},
+		{Key: "review-by", Value: "2025-01-01", Line: 11,
+			StartPosition: 8},
+	}
+	if a := doc.Annotations(); !reflect.DeepEqual(a, exp) {
+		t.Errorf("Got: %#v\n\t Expect: %#v", a, exp)
+	}
+}
+
+func TestDocumentAnnotationsPrefix(t *testing.T) {
+	doc, _ := New("annotations").Parse(annotationText)
+	a := doc.Annotations("review-", "maintainer")
+	if len(a) != 1 || a[0].Key != "review-by" {
+		t.Errorf("Expected only the review-by annotation, got: %#v", a)
+	}
+}
+
+func TestDocumentAnnotationsResemblance(t *testing.T) {
+	// Comments that contain a colon, but are not "key: value" pairs.
+	for _, text := range []string{
+		".. owner:platform-team\n",
+		".. owner: \n",
+		".. the owner: platform-team\n",
+		".. http://example.com/\n",
+	} {
+		doc, _ := New("annotations").Parse(text)
+		if a := doc.Annotations(); a != nil {
+			t.Errorf("%q: Expected no annotations, got: %#v", text, a)
+		}
+	}
+}
+
+func TestDocumentParseKeepsComments(t *testing.T) {
+	doc, _ := New("annotations").Parse(".. owner: platform-team\n")
+	if len(doc.Nodes) != 1 || doc.Nodes[0].NodeType() != parse.NodeComment {
+		t.Errorf("Expected a single comment, got: %#v", doc.Nodes)
+	}
+}
+
+// frontMatterCase is a document beginning with a 20 line license comment.
+const frontMatterCase = "07.00-license-front-matter"
+
+func TestDocumentFrontMatter(t *testing.T) {
+	c, ok := rsttest.Find(frontMatterCase)
+	if !ok {
+		t.Fatalf("Could not find test for %q", frontMatterCase)
+	}
+	text := c.Input
+	doc, _ := New("license").Parse(text,
+		parse.WithFrontMatterComment())
+	fm := doc.FrontMatter
+	if fm == nil {
+		t.Fatal("Expected front matter")
+	}
+	lines := strings.Split(text, "\n")
+	exp := strings.Join(lines[:20], "\n")
+	if fm.Text != exp {
+		t.Errorf("Text: Got: %q\n\t Expect: %q", fm.Text, exp)
+	}
+	if fm.Line != 1 || fm.EndLine != 20 {
+		t.Errorf("Lines: Got: %d-%d, Expect: 1-20", fm.Line, fm.EndLine)
+	}
+	if fm.Comment != doc.Nodes[0] {
+		t.Errorf("Expected the first node as the comment, got: %#v",
+			fm.Comment)
+	}
+}
+
+func TestDocumentFrontMatterOutput(t *testing.T) {
+	// The front matter is not written and not counted, as with any other
+	// comment.
+	c, ok := rsttest.Find(frontMatterCase)
+	if !ok {
+		t.Fatalf("Could not find test for %q", frontMatterCase)
+	}
+	text := c.Input
+	plain, _ := New("license").Parse(text)
+	if plain.FrontMatter != nil {
+		t.Errorf("Expected no front matter without the option, got: %#v",
+			plain.FrontMatter)
+	}
+	front, _ := New("license").Parse(text,
+		parse.WithFrontMatterComment())
+	for _, doc := range []*Document{plain, front} {
+		var buf bytes.Buffer
+		WritePlainText(&buf, doc, TextOptions{})
+		exp := "User Guide\nRead the guide before installing.\n"
+		if buf.String() != exp {
+			t.Errorf("Got: %q\n\t Expect: %q", buf.String(), exp)
+		}
+	}
+	if a, b := plain.Stats(), front.Stats(); !reflect.DeepEqual(a, b) {
+		t.Errorf("Got: %#v\n\t Expect: %#v", b, a)
+	}
+}
+
+func TestDocumentFrontMatterLines(t *testing.T) {
+	for _, test := range []struct {
+		text          string
+		line, endLine parse.Line
+	}{
+		{"..\n   License text\n   continued.\n\nText.\n", 1, 3},
+		{"\n.. License text.\n\nText.\n", 2, 2},
+		{"..\n\nText.\n", 1, 1},
+	} {
+		doc, _ := New("lines").Parse(test.text,
+			parse.WithFrontMatterComment())
+		fm := doc.FrontMatter
+		if fm == nil {
+			t.Errorf("%q: Expected front matter", test.text)
+			continue
+		}
+		if fm.Line != test.line || fm.EndLine != test.endLine {
+			t.Errorf("%q: Got: %d-%d, Expect: %d-%d", test.text, fm.Line,
+				fm.EndLine, test.line, test.endLine)
+		}
+	}
+	for _, text := range []string{
+		"Title\n=====\n\n.. Not front matter.\n",
+		"Text.\n\n.. Not front matter.\n",
+	} {
+		doc, _ := New("lines").Parse(text, parse.WithFrontMatterComment())
+		if doc.FrontMatter != nil {
+			t.Errorf("%q: Expected no front matter, got: %#v", text,
+				doc.FrontMatter)
+		}
+	}
+}
+
+var plainTextInput = `Installing
+==========
+
+Download the source from https://example.com/go-rst.tar.gz and
+build it::
+
+    go build ./...
+
+Run the tests with: ::
+
+    go test ./...
+
+.. This comment is not prose.
+
+Term
+    The definition
+    of the term.
+
+- Report bugs to mailto:bugs@example.com
+  or on the tracker.
+`
+
+func TestWritePlainText(t *testing.T) {
+	doc, _ := New("install.rst").Parse(plainTextInput)
+	var buf bytes.Buffer
+	if err := WritePlainText(&buf, doc, TextOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	exp := `Installing
+Download the source from and build it:
+Run the tests with:
+Term
+The definition of the term.
+Report bugs to or on the tracker.
+`
+	if buf.String() != exp {
+		t.Errorf("Got:\n%s\n\t Expect:\n%s", buf.String(), exp)
+	}
+	for _, s := range []string{"go build", "go test", "://", "mailto", "comment"} {
+		if strings.Contains(buf.String(), s) {
+			t.Errorf("Output contains %q", s)
+		}
+	}
+}
+
+func TestWritePlainTextOptionList(t *testing.T) {
+	// The options are not prose, only their descriptions are written.
+	doc, _ := New("usage.rst").Parse("-f FILE, --file=FILE  Read the " +
+		"input from FILE.\n-v                    Verbose output.\n")
+	var buf bytes.Buffer
+	WritePlainText(&buf, doc, TextOptions{})
+	exp := "Read the input from FILE.\nVerbose output.\n"
+	if buf.String() != exp {
+		t.Errorf("Got:\n%s\n\t Expect:\n%s", buf.String(), exp)
+	}
+}
+
+func TestWritePlainTextLinePrefix(t *testing.T) {
+	doc, _ := New("install.rst").Parse(plainTextInput)
+	var buf bytes.Buffer
+	WritePlainText(&buf, doc, TextOptions{LinePrefix: true})
+	exp := []string{"install.rst:1:", "install.rst:4:", "install.rst:9:",
+		"install.rst:15:", "install.rst:16:", "install.rst:19:"}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != len(exp) {
+		t.Fatalf("Got %d lines, Expect: %d\n%s", len(lines), len(exp),
+			buf.String())
+	}
+	for i, line := range lines {
+		if !strings.HasPrefix(line, exp[i]) {
+			t.Errorf("Got: %q, Expect prefix: %q", line, exp[i])
+		}
+	}
+}
+
+func TestWritePlainTextNewline(t *testing.T) {
+	doc, _ := New("install.rst").Parse(plainTextInput)
+	var lf, crlf bytes.Buffer
+	WritePlainText(&lf, doc, TextOptions{})
+	if err := WritePlainText(&crlf, doc, TextOptions{Newline: CRLF}); err != nil {
+		t.Fatal(err)
+	}
+	exp := strings.Replace(lf.String(), "\n", "\r\n", -1)
+	if crlf.String() != exp {
+		t.Errorf("Got: %q\n\t Expect: %q", crlf.String(), exp)
+	}
+	if err := WritePlainText(&lf, doc, TextOptions{Newline: "\r"}); err == nil {
+		t.Error("Expected an error for an invalid newline")
+	}
+}
+
+func TestWritePlainTextNoDefinition(t *testing.T) {
+	// A tree built without the parser can have a term with no definition.
+	list := &parse.DefinitionListNode{NodeList: parse.NodeList{
+		&parse.DefinitionListItemNode{
+			Term: &parse.DefinitionTermNode{Text: "Term"},
+		},
+	}}
+	doc := &Document{name: "terms.rst",
+		Tree: &parse.Tree{Nodes: parse.NodeList{list}}}
+	var buf bytes.Buffer
+	if err := WritePlainText(&buf, doc, TextOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "Term\n" {
+		t.Errorf("Got: %q, Expect: %q", buf.String(), "Term\n")
+	}
+}
+
+// failWriter fails every write after the first n bytes.
+type failWriter struct{ n int }
+
+func (f *failWriter) Write(p []byte) (int, error) {
+	if len(p) > f.n {
+		return f.n, errors.New("write failed")
+	}
+	f.n -= len(p)
+	return len(p), nil
+}
+
+func TestLineWriterError(t *testing.T) {
+	lw, _ := newLineWriter(&failWriter{n: 3}, "")
+	lw.line("one")
+	lw.flush()
+	lw.line("two")
+	if err := lw.flush(); err == nil || err.Error() != "write failed" {
+		t.Errorf("Got: %v, Expect: write failed", err)
+	}
+}
+
+var statsInput = `Guide
+=====
+
+Read the guide, don't skip it — twice.
+
+Install
+-------
+
+Run this::
+
+    go get example.com/rst
+
+    go test ./...
+
+日本語の説明です。
+
+Hangul
+------
+
+한국어 문서 3.14 http://example.com
+`
+
+func TestDocumentStats(t *testing.T) {
+	doc, _ := New("stats").Parse(statsInput)
+	install := SectionStats{Title: "Install", Level: 2, Line: 6,
+		Counts: Counts{Words: 11, LiteralLines: 2}}
+	hangul := SectionStats{Title: "Hangul", Level: 2, Line: 17,
+		Counts: Counts{Words: 4}}
+	exp := Stats{
+		Counts: Counts{Words: 23, LiteralLines: 2},
+		Sections: []SectionStats{{Title: "Guide", Level: 1, Line: 1,
+			Counts:   Counts{Words: 23, LiteralLines: 2},
+			Sections: []SectionStats{install, hangul}}},
+	}
+	if s := doc.Stats(); !reflect.DeepEqual(s, exp) {
+		t.Errorf("Got: %#v\n\t Expect: %#v", s, exp)
+	}
+}
+
+func TestCountsReadingTime(t *testing.T) {
+	c := Counts{Words: 450}
+	if d := c.ReadingTime(200); d != 135*time.Second {
+		t.Errorf("Got: %s, Expect: 2m15s", d)
+	}
+	if d := c.ReadingTime(0); d != 0 {
+		t.Errorf("Got: %s, Expect: 0s", d)
+	}
+}
+
+func TestRender(t *testing.T) {
+	doc, _ := New("install.rst").Parse(plainTextInput)
+	var got, exp bytes.Buffer
+	WritePlainText(&exp, doc, TextOptions{LinePrefix: true, Newline: CRLF})
+	err := Render(&got, doc, "text", map[string]interface{}{
+		"line-prefix": true,
+		"newline":     CRLF,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.String() != exp.String() {
+		t.Errorf("Got: %q\n\t Expect: %q", got.String(), exp.String())
+	}
+}
+
+// TestRenderEmpty renders documents without nodes with each writer. A writer
+// must not write anything for them, so that its output can be concatenated.
+func TestRenderEmpty(t *testing.T) {
+	inputs := []string{"", "\n\n\n", "  \n\t\n"}
+	for format := range writers {
+		for _, input := range inputs {
+			doc, err := New("empty.rst").Parse(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(doc.Nodes) != 0 || len(doc.Messages) != 0 {
+				t.Errorf("%q: Got %d nodes and %d messages, Expect: none",
+					input, len(doc.Nodes), len(doc.Messages))
+			}
+			var b bytes.Buffer
+			if err := Render(&b, doc, format, nil); err != nil {
+				t.Errorf("%s: %q: %s", format, input, err)
+			} else if b.Len() != 0 {
+				t.Errorf("%s: %q: Got: %q, Expect no output", format, input,
+					b.String())
+			}
+		}
+		var b bytes.Buffer
+		if err := Render(&b, New("unparsed.rst"), format, nil); err != nil ||
+			b.Len() != 0 {
+			t.Errorf("%s: unparsed document: Got: %q, %v", format,
+				b.String(), err)
+		}
+	}
+}
+
+func TestRenderErrors(t *testing.T) {
+	doc, _ := New("install.rst").Parse(plainTextInput)
+	tests := []struct {
+		format string
+		opts   map[string]interface{}
+		err    string
+	}{
+		{"html", nil, `unknown format "html"; known formats are text`},
+		{"text", map[string]interface{}{"line-prefix": "yes"},
+			`option "line-prefix" for format "text" must be a bool, ` +
+				`not string`},
+		{"text", map[string]interface{}{"width": 72},
+			`unknown option "width" for format "text"`},
+	}
+	for _, test := range tests {
+		var b bytes.Buffer
+		err := Render(&b, doc, test.format, test.opts)
+		if err == nil || err.Error() != test.err {
+			t.Errorf("Got: %v\n\t Expect: %s", err, test.err)
+		}
+		if b.Len() > 0 {
+			t.Errorf("%s: Expected no output, got: %q", test.err, b.String())
+		}
+	}
+}
+
+var hashInput = `Title
+=====
+
+Paragraph one.
+
+.. a comment
+
+Paragraph two.
+`
+
+func TestDocumentHash(t *testing.T) {
+	hash := func(text string, opts HashOptions) [32]byte {
+		doc, _ := New("hash.rst").Parse(text)
+		return doc.Hash(opts)
+	}
+	positions := HashOptions{ExcludePositions: true}
+	all := HashOptions{ExcludePositions: true, ExcludeComments: true}
+	moved := "Title\n=====\n\n\n\nParagraph one.\n\n..    a comment\n\n" +
+		"Paragraph two.\n"
+	uncommented := "Title\n=====\n\nParagraph one.\n\nParagraph two.\n"
+	edited := strings.Replace(hashInput, "two", "three", 1)
+	tests := []struct {
+		text  string
+		opts  HashOptions
+		equal bool
+	}{
+		{moved, HashOptions{}, false},
+		{moved, positions, true},
+		{uncommented, positions, false},
+		{uncommented, all, true},
+		{edited, HashOptions{}, false},
+		{edited, all, false},
+	}
+	for _, test := range tests {
+		got := hash(test.text, test.opts) == hash(hashInput, test.opts)
+		if got != test.equal {
+			t.Errorf("%+v: Got equal hashes %t, Expect: %t\n%s", test.opts,
+				got, test.equal, test.text)
+		}
+	}
+}
+
+func TestDocumentHashStable(t *testing.T) {
+	doc, _ := New("hash.rst").Parse(hashInput)
+	exp := doc.Hash(HashOptions{})
+	for i := 0; i < 100; i++ {
+		doc, _ := New("hash.rst").Parse(hashInput)
+		if got := doc.Hash(HashOptions{}); got != exp {
+			t.Fatalf("Parse %d: Got hash %x, Expect: %x", i, got, exp)
+		}
+	}
+	// The encoding must not change between releases.
+	golden := "ab8779d400277458780bec0b7300126a" +
+		"3e5ca2ece1163596a5f59afca813de1e"
+	if got := fmt.Sprintf("%x", exp); got != golden {
+		t.Errorf("Got hash %s, Expect: %s", got, golden)
+	}
+	empty, _ := New("empty.rst").Parse("")
+	if New("empty.rst").Hash(HashOptions{}) != empty.Hash(HashOptions{}) {
+		t.Error("Expected an unparsed document to hash like an empty one")
+	}
+}
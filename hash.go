@@ -0,0 +1,134 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package rst
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"reflect"
+	"strings"
+
+	"github.com/demizer/go-rst/parse"
+)
+
+// HashOptions select the parts of the parse tree left out of the hash
+// returned by Document.Hash.
+type HashOptions struct {
+	// ExcludePositions leaves out the lines and start positions of the
+	// nodes and the indentation of section titles, so that moving text
+	// without changing it, such as by adding blank lines, does not change
+	// the hash.
+	ExcludePositions bool
+
+	// ExcludeComments leaves out comments.
+	ExcludeComments bool
+}
+
+// Hash returns the SHA-256 hash of the parse tree of the document. Documents
+// with equal trees have equal hashes, so the hash can be used to find out if
+// a document needs to be rendered again. The node IDs are not hashed, they
+// only number the nodes in document order.
+//
+// The tree is hashed in a canonical encoding: each field of a node is written
+// by its JSON name, in the order the fields are declared, and strings and
+// lists are prefixed by their length. The hash does not depend on the Go
+// version or on the order of map iteration. A document that is not parsed
+// has the hash of an empty document.
+func (d *Document) Hash(opts HashOptions) (sum [32]byte) {
+	var nl parse.NodeList
+	if d.Tree != nil {
+		nl = d.Nodes
+	}
+	e := &hashEncoder{h: sha256.New(), opts: opts}
+	e.value(reflect.ValueOf(nl))
+	copy(sum[:], e.h.Sum(nil))
+	return
+}
+
+// hashEncoder writes the canonical encoding of parse tree values to h.
+type hashEncoder struct {
+	h    hash.Hash
+	opts HashOptions
+	buf  [binary.MaxVarintLen64]byte
+}
+
+var nodeListType = reflect.TypeOf(parse.NodeList(nil))
+
+// positionFields are the JSON names of the fields left out by
+// HashOptions.ExcludePositions.
+var positionFields = map[string]bool{
+	"line":          true,
+	"startPosition": true,
+	"indentLength":  true,
+}
+
+func (e *hashEncoder) int(n int64) {
+	e.h.Write(e.buf[:binary.PutVarint(e.buf[:], n)])
+}
+
+func (e *hashEncoder) string(s string) {
+	e.int(int64(len(s)))
+	e.h.Write([]byte(s))
+}
+
+func (e *hashEncoder) value(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			e.int(0)
+			return
+		}
+		e.int(1)
+		e.value(v.Elem())
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+			if name == "id" ||
+				e.opts.ExcludePositions && positionFields[name] {
+				continue
+			}
+			e.string(name)
+			e.value(v.Field(i))
+		}
+	case reflect.Slice:
+		if v.Type() == nodeListType {
+			v = reflect.ValueOf(e.nodes(v.Interface().(parse.NodeList)))
+		}
+		e.int(int64(v.Len()))
+		for i := 0; i < v.Len(); i++ {
+			e.value(v.Index(i))
+		}
+	case reflect.String:
+		e.string(v.String())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
+		e.int(v.Int())
+	case reflect.Bool:
+		if v.Bool() {
+			e.int(1)
+		} else {
+			e.int(0)
+		}
+	default:
+		panic(fmt.Sprintf("rst: cannot hash a %s", v.Type()))
+	}
+}
+
+// nodes returns the nodes of nl that are hashed.
+func (e *hashEncoder) nodes(nl parse.NodeList) parse.NodeList {
+	if !e.opts.ExcludeComments {
+		return nl
+	}
+	var hashed parse.NodeList
+	for _, n := range nl {
+		if _, ok := n.(*parse.CommentNode); !ok {
+			hashed = append(hashed, n)
+		}
+	}
+	return hashed
+}
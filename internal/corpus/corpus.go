@@ -0,0 +1,17 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+// Package corpus embeds the test fixtures of the go-rst packages, so the
+// tests read them without a file system, as in WebAssembly builds. It is
+// imported by the tests of the parse package, which cannot import rsttest, and
+// by package rsttest, which provides the fixtures to other packages.
+package corpus
+
+import "embed"
+
+// Files contains the test-* directories of fixtures, with paths such as
+// "test-comment/00-good/00.00-comment.rst".
+//
+//go:embed test-*
+var Files embed.FS
@@ -0,0 +1,183 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+// Package expect decodes the expected nodes of the go-rst test fixtures, the
+// -nodes.json files, for the tests of package parse and for package rsttest.
+// It does not import package parse, which gives its node types to Decode, so
+// that the tests of package parse can use it.
+package expect
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Types maps the name of each node type of the parser, such as
+// "NodeParagraph", to the struct of the nodes of that type.
+type Types map[string]reflect.Type
+
+// unsetStartPosition is the start position of a decoded node that does not
+// specify a startPosition.
+const unsetStartPosition = -1
+
+// Omitted returns true if the difference between the value a of the field
+// named field of an expected node and the value b of the parsed node is not a
+// difference: a startPosition omitted from the expected nodes matches a parsed
+// node at position zero or one, since most nodes begin at position one in the
+// line.
+func Omitted(field string, a, b interface{}) bool {
+	av, bv := reflect.ValueOf(a), reflect.ValueOf(b)
+	return field == "StartPosition" && av.Kind() == reflect.Int &&
+		av.Int() == unsetStartPosition && bv.Kind() == reflect.Int &&
+		(bv.Int() == 0 || bv.Int() == 1)
+}
+
+// optionalNodeFields are the fields that can be omitted from the expected
+// nodes. An omitted field is decoded as its zero value, the parsed node must
+// then also have the zero value, except an omitted startPosition, which is
+// decoded as unsetStartPosition.
+var optionalNodeFields = map[string]bool{
+	"startPosition": true,
+	"line":          true,
+	"overLine":      true,
+	"nodeList":      true,
+	"text":          true,
+	"length":        true,
+	"indentLength":  true,
+	"classifiers":   true,
+	"argument":      true,
+}
+
+// Decode sets the node list pointed to by nodes to the nodes of eTree, the
+// decoded JSON list of a -nodes.json file, using the node structs of types.
+// Each node is an object of the fields of the node struct, keyed by their json
+// names. The fields whose types implement json.Unmarshaler or
+// encoding.TextUnmarshaler, such as types, messages, and levels, are decoded
+// by those methods, so they can be given by name, as in "NodeParagraph". An
+// error is returned if eTree is not a valid list of nodes.
+func Decode(eTree []interface{}, nodes interface{}, types Types) error {
+	return types.decodeNodeField(reflect.ValueOf(nodes).Elem(), eTree)
+}
+
+// decodeNode returns a pointer to the node struct decoded from eNode. The
+// struct of the node is selected using the "type" field.
+func (types Types) decodeNode(eNode interface{}) (reflect.Value, error) {
+	fields, ok := eNode.(map[string]interface{})
+	if !ok {
+		return reflect.Value{}, fmt.Errorf("node is not an object: %v",
+			eNode)
+	}
+	nType, ok := fields["type"].(string)
+	if !ok || types[nType] == nil {
+		return reflect.Value{}, fmt.Errorf("unknown node type %v",
+			fields["type"])
+	}
+	nVal := reflect.New(types[nType])
+	if err := types.decodeNodeFields(fields, nVal.Elem()); err != nil {
+		return reflect.Value{}, err
+	}
+	return nVal, nil
+}
+
+// decodeNodeFields sets the fields of the node struct nVal from the expected
+// node fields.
+func (types Types) decodeNodeFields(fields map[string]interface{},
+	nVal reflect.Value) error {
+	known := make(map[string]bool)
+	for i := 0; i < nVal.NumField(); i++ {
+		name := nVal.Type().Field(i).Tag.Get("json")
+		if name == "" {
+			return fmt.Errorf("%s field %q has no json tag; "+
+				"Check struct tags!", nVal.Type().Name(),
+				nVal.Type().Field(i).Name)
+		}
+		known[name] = true
+		val, in := fields[name]
+		if !in {
+			if !optionalNodeFields[name] {
+				return fmt.Errorf("node ID=%v missing field %q",
+					fields["id"], name)
+			}
+			if name == "startPosition" {
+				nVal.Field(i).SetInt(unsetStartPosition)
+			}
+			continue
+		}
+		if err := types.decodeNodeField(nVal.Field(i), val); err != nil {
+			return fmt.Errorf("node ID=%v field %q: %s",
+				fields["id"], name, err)
+		}
+	}
+	for name := range fields {
+		if !known[name] {
+			return fmt.Errorf("node ID=%v: %s has no field %q",
+				fields["id"], nVal.Type().Name(), name)
+		}
+	}
+	return nil
+}
+
+var runeType = reflect.TypeOf(rune(0))
+
+// decodeNodeField sets the node field f from the expected value val.
+func (types Types) decodeNodeField(f reflect.Value, val interface{}) error {
+	if val == nil {
+		// Null nodes, such as a missing section overline.
+		return nil
+	}
+	switch u := f.Addr().Interface().(type) {
+	case json.Unmarshaler:
+		data, err := json.Marshal(val)
+		if err != nil {
+			return err
+		}
+		return u.UnmarshalJSON(data)
+	case encoding.TextUnmarshaler:
+		return u.UnmarshalText([]byte(fmt.Sprint(val)))
+	}
+	switch {
+	case f.Type() == runeType:
+		r, _ := utf8.DecodeRuneInString(fmt.Sprint(val))
+		f.SetInt(int64(r))
+	case f.Kind() == reflect.String:
+		text, ok := val.(string)
+		if !ok {
+			return fmt.Errorf("%v is not a string", val)
+		}
+		f.SetString(norm.NFC.String(text))
+	case f.Kind() == reflect.Slice:
+		eList, ok := val.([]interface{})
+		if !ok {
+			return fmt.Errorf("%v is not a list", val)
+		}
+		nl := reflect.MakeSlice(f.Type(), 0, len(eList))
+		for _, eNode := range eList {
+			n, err := types.decodeNode(eNode)
+			if err != nil {
+				return err
+			}
+			nl = reflect.Append(nl, n)
+		}
+		f.Set(nl)
+	case f.Kind() == reflect.Ptr:
+		eFields, ok := val.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%v is not an object", val)
+		}
+		f.Set(reflect.New(f.Type().Elem()))
+		return types.decodeNodeFields(eFields, f.Elem())
+	default:
+		num, ok := val.(float64)
+		if !ok {
+			return fmt.Errorf("%v is not a number", val)
+		}
+		f.SetInt(int64(num))
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+// Package lint checks parsed reStructuredText documents against style rules.
+// Style rules are implemented outside of the parser using the Rule interface,
+// so house styles do not need changes to the parser.
+package lint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/demizer/go-rst"
+	"github.com/demizer/go-rst/parse"
+)
+
+// Message is a problem found in a document by a Rule.
+type Message struct {
+	Rule  string // The name of the rule that found the problem
+	Level parse.SystemMessageLevel
+	parse.Line
+	Column int // Column in runes, starting at 1, or 0 for the whole line
+	Text   string
+}
+
+// String returns the message in the form "line: LEVEL: text (rule)".
+func (m Message) String() string {
+	return fmt.Sprintf("%d: %s: %s (%s)", m.Line, m.Level, m.Text, m.Rule)
+}
+
+// sourceEchoWidth is the number of columns of the source line written by
+// FormatMessageWithSource. Longer lines are cut around the column of the
+// message.
+const sourceEchoWidth = 80
+
+// FormatMessageWithSource returns m in the form returned by String, followed
+// by the line of source the message refers to and, if the message has a
+// column, a caret below the column. The line and the caret are indented by
+// four spaces. Tabs are expanded to eight column tab stops so that the caret
+// lines up, and invisible control characters are written as "?" so that the
+// line is displayed as it is stored. A line longer than sourceEchoWidth
+// columns is cut to a window centered on the caret, with "..." marking the
+// cut text. If source has no such line, only the message is returned.
+func FormatMessageWithSource(m Message, source string) string {
+	lines := strings.Split(source, "\n")
+	if m.Line < 1 || int(m.Line) > len(lines) {
+		return m.String()
+	}
+	var cells []rune
+	caret, column := -1, 0
+	for _, r := range strings.TrimSuffix(lines[m.Line-1], "\r") {
+		if column++; column == m.Column {
+			caret = len(cells)
+		}
+		switch {
+		case r == '\t':
+			cells = append(cells, ' ')
+			for len(cells)%8 != 0 {
+				cells = append(cells, ' ')
+			}
+		case parse.IsInvisibleControl(r):
+			cells = append(cells, '?')
+		default:
+			cells = append(cells, r)
+		}
+	}
+	if m.Column > column {
+		caret = len(cells)
+	}
+	start, end := 0, len(cells)
+	if end > sourceEchoWidth {
+		if caret > sourceEchoWidth/2 {
+			start = caret - sourceEchoWidth/2
+		}
+		if start > end-sourceEchoWidth {
+			start = end - sourceEchoWidth
+		}
+		end = start + sourceEchoWidth
+	}
+	echo, pad := string(cells[start:end]), caret-start
+	if start > 0 {
+		echo, pad = "..."+echo, pad+3
+	}
+	if end < len(cells) {
+		echo += "..."
+	}
+	s := m.String() + "\n    " + echo
+	if m.Column > 0 {
+		s += "\n    " + strings.Repeat(" ", pad) + "^"
+	}
+	return s
+}
+
+// Rule is a style rule. Check returns the problems found in doc.
+type Rule interface {
+	Check(doc *rst.Document) []Message
+}
+
+type byLine []Message
+
+func (b byLine) Len() int           { return len(b) }
+func (b byLine) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+func (b byLine) Less(i, j int) bool { return b[i].Line < b[j].Line }
+
+// Check checks doc against rules and returns the messages of all rules in
+// order by line. Messages on the same line are in the order of rules.
+func Check(doc *rst.Document, rules ...Rule) (m []Message) {
+	for _, r := range rules {
+		m = append(m, r.Check(doc)...)
+	}
+	sort.Stable(byLine(m))
+	return
+}
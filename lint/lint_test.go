@@ -0,0 +1,225 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package lint
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/demizer/go-rst"
+	"github.com/demizer/go-rst/parse"
+)
+
+func parseDoc(t *testing.T, text string) *rst.Document {
+	doc, err := rst.New("lint.rst").Parse(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+// messageLines returns the line and rule of each message.
+func messageLines(m []Message) (s []string) {
+	for _, msg := range m {
+		s = append(s, msg.Line.String()+":"+msg.Rule)
+	}
+	return
+}
+
+var adornmentInput = `#####
+Title
+#####
+
+*******
+Chapter
+*******
+
+Section
+=======
+
+Text.
+`
+
+func TestAdornmentSequence(t *testing.T) {
+	doc := parseDoc(t, adornmentInput)
+	if m := AdornmentSequence("#*=-^", 2).Check(doc); m != nil {
+		t.Errorf("Expected no messages, got: %v", m)
+	}
+}
+
+func TestAdornmentSequenceBad(t *testing.T) {
+	doc := parseDoc(t, adornmentInput)
+	var text []string
+	for _, m := range AdornmentSequence("#-", 1).Check(doc) {
+		if m.Level != parse.LevelError {
+			t.Errorf("Got level %s, Expect: ERROR", m.Level)
+		}
+		text = append(text, m.String())
+	}
+	exp := []string{
+		`5: ERROR: Section level 2 is adorned with '*', expected '-'. ` +
+			`(adornment-sequence)`,
+		`5: ERROR: Section level 2 must not have an overline. ` +
+			`(adornment-sequence)`,
+		`9: ERROR: Section level 3 is deeper than the 2 levels allowed. ` +
+			`(adornment-sequence)`,
+	}
+	if !reflect.DeepEqual(text, exp) {
+		t.Errorf("Got:\n%s\n\t Expect:\n%s", strings.Join(text, "\n"),
+			strings.Join(exp, "\n"))
+	}
+}
+
+func TestAdornmentSequenceMissingOverline(t *testing.T) {
+	doc := parseDoc(t, "Title\n=====\n\nText.\n")
+	m := AdornmentSequence("=", 1).Check(doc)
+	if len(m) != 1 || !strings.Contains(m[0].Text, "must have an overline") {
+		t.Errorf("Expected a missing overline message, got: %v", m)
+	}
+}
+
+func TestMaxLineLength(t *testing.T) {
+	long := strings.Repeat("word ", 10)
+	doc := parseDoc(t, "Short.\n\n"+long+"\n\nExample::\n\n    "+long+
+		"\n\n+-----+\n| "+long+"|\n+-----+\n\n"+long+"\n")
+	exp := []string{"3:max-line-length", "13:max-line-length"}
+	if got := messageLines(MaxLineLength(40).Check(doc)); !reflect.DeepEqual(got, exp) {
+		t.Errorf("Got: %v, Expect: %v", got, exp)
+	}
+}
+
+func TestMaxLineLengthRunes(t *testing.T) {
+	doc := parseDoc(t, strings.Repeat("é", 10)+"\n")
+	if m := MaxLineLength(10).Check(doc); m != nil {
+		t.Errorf("Expected no messages, got: %v", m)
+	}
+}
+
+func TestRequireAnnotation(t *testing.T) {
+	doc := parseDoc(t, "Text.\n\n.. owner-team: docs\n\n"+
+		".. owner: platform-team\n")
+	if m := RequireAnnotation("owner").Check(doc); m != nil {
+		t.Errorf("Expected no messages, got: %v", m)
+	}
+}
+
+func TestRequireAnnotationMissing(t *testing.T) {
+	// A key beginning with the required key is not the annotation.
+	doc := parseDoc(t, "Text.\n\n.. owner-team: docs\n")
+	var text []string
+	for _, m := range RequireAnnotation("owner").Check(doc) {
+		text = append(text, m.String())
+	}
+	exp := []string{
+		`1: ERROR: Missing annotation "owner". (require-annotation)`}
+	if !reflect.DeepEqual(text, exp) {
+		t.Errorf("Got: %v, Expect: %v", text, exp)
+	}
+}
+
+func TestSystemMessages(t *testing.T) {
+	// The info message about the short underline of "Ab" is not reported.
+	doc := parseDoc(t, "Ab\n=\n\nTitle\n===\n\nText.\n\n----\n")
+	var text []string
+	for _, m := range SystemMessages(parse.LevelWarning).Check(doc) {
+		text = append(text, m.String())
+	}
+	exp := []string{
+		"4: WARNING: Title underline too short. (system-message)",
+		"9: ERROR: Document may not end with a transition. " +
+			"(system-message)",
+	}
+	if !reflect.DeepEqual(text, exp) {
+		t.Errorf("Got:\n%s\n\t Expect:\n%s", strings.Join(text, "\n"),
+			strings.Join(exp, "\n"))
+	}
+}
+
+func TestSystemMessagesReportLevel(t *testing.T) {
+	// An info message for the invisible control on line 4, a warning on
+	// line 1, and an error on line 6.
+	doc := parseDoc(t, "Title\n===\n\nTe\u200bxt.\n\n----\n")
+	tests := []struct {
+		min   parse.SystemMessageLevel
+		lines []string
+	}{
+		{parse.LevelInfo, []string{"1:system-message", "4:system-message",
+			"6:system-message"}},
+		{parse.LevelWarning, []string{"1:system-message",
+			"6:system-message"}},
+		{parse.LevelError, []string{"6:system-message"}},
+		{parse.LevelSevere, nil},
+	}
+	for _, test := range tests {
+		m := SystemMessages(test.min).Check(doc)
+		if got := messageLines(m); !reflect.DeepEqual(got, test.lines) {
+			t.Errorf("%s: Got: %v, Expect: %v", test.min, got,
+				test.lines)
+		}
+	}
+}
+
+func TestCheckOrder(t *testing.T) {
+	doc := parseDoc(t, "Title\n=====\n\n"+strings.Repeat("x", 20)+"\n")
+	m := Check(doc, MaxLineLength(10), AdornmentSequence("-", 0))
+	exp := []string{"1:adornment-sequence", "4:max-line-length"}
+	if got := messageLines(m); !reflect.DeepEqual(got, exp) {
+		t.Errorf("Got: %v, Expect: %v", got, exp)
+	}
+}
+
+func TestInvisibleControls(t *testing.T) {
+	doc := parseDoc(t, "Text.\n\n`a‮gpj.exe <https://example.com/>`_\n\n"+
+		"::\n\n    zero​width\n")
+	var text []string
+	for _, m := range InvisibleControls().Check(doc) {
+		text = append(text, m.String())
+	}
+	exp := []string{
+		"3: WARNING: Invisible control character U+202E at column 3. " +
+			"(invisible-control)",
+		"7: WARNING: Invisible control character U+200B at column 9. " +
+			"(invisible-control)",
+	}
+	if !reflect.DeepEqual(text, exp) {
+		t.Errorf("Got:\n%s\n\t Expect:\n%s", strings.Join(text, "\n"),
+			strings.Join(exp, "\n"))
+	}
+}
+
+func TestFormatMessageWithSource(t *testing.T) {
+	m := Message{Rule: "r", Level: parse.LevelWarning, Line: 2, Column: 5,
+		Text: "Text."}
+	got := FormatMessageWithSource(m, "First.\n\ttab\u200bbed\n")
+	exp := "2: WARNING: Text. (r)\n            tab?bed\n               ^"
+	if got != exp {
+		t.Errorf("Got:\n%s\n\t Expect:\n%s", got, exp)
+	}
+}
+
+func TestFormatMessageWithSourceLong(t *testing.T) {
+	// The window of 80 columns is centered on column 126.
+	line := strings.Repeat("0123456789", 30)
+	m := Message{Rule: "r", Level: parse.LevelError, Line: 1, Column: 126,
+		Text: "Text."}
+	exp := "1: ERROR: Text. (r)\n    ..." + line[85:165] + "...\n    " +
+		strings.Repeat(" ", 43) + "^"
+	if got := FormatMessageWithSource(m, line); got != exp {
+		t.Errorf("Got:\n%s\n\t Expect:\n%s", got, exp)
+	}
+}
+
+func TestFormatMessageWithSourceNoColumn(t *testing.T) {
+	m := Message{Rule: "r", Level: parse.LevelError, Line: 1, Text: "Text."}
+	exp := "1: ERROR: Text. (r)\n    Title"
+	if got := FormatMessageWithSource(m, "Title\n=====\n"); got != exp {
+		t.Errorf("Got:\n%s\n\t Expect:\n%s", got, exp)
+	}
+	m.Line = 5
+	if got := FormatMessageWithSource(m, "Title\n"); got != m.String() {
+		t.Errorf("Got: %q, Expect: %q", got, m.String())
+	}
+}
@@ -0,0 +1,223 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package lint
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/demizer/go-rst"
+	"github.com/demizer/go-rst/parse"
+)
+
+type adornmentSequence struct {
+	runes          []rune
+	overlineLevels int
+}
+
+// AdornmentSequence returns a Rule requiring section level n to be adorned
+// with the nth rune of chars. The first overlineLevels levels must have an
+// overline and deeper levels must not. Sections deeper than the length of
+// chars are reported. For example, AdornmentSequence("#*=-^", 2) requires
+// the style used by the Python documentation.
+func AdornmentSequence(chars string, overlineLevels int) Rule {
+	return &adornmentSequence{runes: []rune(chars),
+		overlineLevels: overlineLevels}
+}
+
+func (a *adornmentSequence) Check(doc *rst.Document) (m []Message) {
+	// All sections of a level have the same adornment, so only the first
+	// section of each level is checked.
+	checked := make(map[int]bool)
+	walkSections(doc.Nodes, func(s *parse.SectionNode) {
+		if checked[s.Level] {
+			return
+		}
+		checked[s.Level] = true
+		line := s.Title.Line
+		if s.OverLine != nil {
+			line = s.OverLine.Line
+		}
+		report := func(format string, a ...interface{}) {
+			m = append(m, Message{
+				Rule:  "adornment-sequence",
+				Level: parse.LevelError,
+				Line:  line,
+				Text:  fmt.Sprintf(format, a...),
+			})
+		}
+		if s.Level > len(a.runes) {
+			report("Section level %d is deeper than the %d levels "+
+				"allowed.", s.Level, len(a.runes))
+			return
+		}
+		if r := a.runes[s.Level-1]; s.UnderLine.Rune != r {
+			report("Section level %d is adorned with %q, expected %q.",
+				s.Level, s.UnderLine.Rune, r)
+		}
+		if s.Level <= a.overlineLevels && s.OverLine == nil {
+			report("Section level %d must have an overline.", s.Level)
+		} else if s.Level > a.overlineLevels && s.OverLine != nil {
+			report("Section level %d must not have an overline.",
+				s.Level)
+		}
+	})
+	return
+}
+
+// walkSections calls fn for each section in nl, in document order.
+func walkSections(nl parse.NodeList, fn func(*parse.SectionNode)) {
+	for _, n := range nl {
+		if s, ok := n.(*parse.SectionNode); ok {
+			fn(s)
+			walkSections(s.NodeList, fn)
+		}
+	}
+}
+
+type maxLineLength struct {
+	n int
+}
+
+// MaxLineLength returns a Rule limiting lines to n runes. Literal blocks and
+// grid tables are not checked, because their lines cannot be wrapped.
+func MaxLineLength(n int) Rule {
+	return &maxLineLength{n: n}
+}
+
+// gridTableBorder matches the top border of a grid table.
+var gridTableBorder = regexp.MustCompile(`^\+(-+\+)+$`)
+
+func (l *maxLineLength) Check(doc *rst.Document) (m []Message) {
+	lines := strings.Split(doc.Source(), "\n")
+	for i := 0; i < len(lines); i++ {
+		text := strings.TrimSpace(lines[i])
+		if gridTableBorder.MatchString(text) {
+			for i+1 < len(lines) && strings.TrimSpace(lines[i+1]) != "" {
+				i++
+			}
+			continue
+		}
+		if c := utf8.RuneCountInString(lines[i]); c > l.n {
+			m = append(m, Message{
+				Rule:   "max-line-length",
+				Level:  parse.LevelError,
+				Line:   parse.Line(i + 1),
+				Column: l.n + 1,
+				Text: fmt.Sprintf("Line is %d characters long, "+
+					"the maximum is %d.", c, l.n),
+			})
+		}
+		if strings.HasSuffix(text, "::") {
+			// The indented lines that follow are a literal block.
+			indent := indentWidth(lines[i])
+			for i+1 < len(lines) && (strings.TrimSpace(lines[i+1]) == "" ||
+				indentWidth(lines[i+1]) > indent) {
+				i++
+			}
+		}
+	}
+	return
+}
+
+// indentWidth returns the number of spaces at the start of line.
+func indentWidth(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+type invisibleControls struct{}
+
+// InvisibleControls returns a Rule reporting each zero-width or bidirectional
+// control character in the document, as defined by parse.IsInvisibleControl.
+// The parser only reports the first one of each paragraph, while this rule
+// checks every line of input, including literal blocks and comments.
+func InvisibleControls() Rule {
+	return invisibleControls{}
+}
+
+func (invisibleControls) Check(doc *rst.Document) (m []Message) {
+	for num, line := range strings.Split(doc.Source(), "\n") {
+		column := 0
+		for _, r := range line {
+			column++
+			if !parse.IsInvisibleControl(r) {
+				continue
+			}
+			m = append(m, Message{
+				Rule:   "invisible-control",
+				Level:  parse.LevelWarning,
+				Line:   parse.Line(num + 1),
+				Column: column,
+				Text: fmt.Sprintf("Invisible control character %U at "+
+					"column %d.", r, column),
+			})
+		}
+	}
+	return
+}
+
+type requireAnnotation struct {
+	key string
+}
+
+// RequireAnnotation returns a Rule requiring the document to have an
+// annotation with the key key, such as ".. owner: platform-team", as returned
+// by Document.Annotations. A missing annotation is reported at line 1.
+func RequireAnnotation(key string) Rule {
+	return &requireAnnotation{key: key}
+}
+
+func (r *requireAnnotation) Check(doc *rst.Document) []Message {
+	for _, a := range doc.Annotations(r.key) {
+		if a.Key == r.key {
+			return nil
+		}
+	}
+	return []Message{{
+		Rule:  "require-annotation",
+		Level: parse.LevelError,
+		Line:  1,
+		Text:  fmt.Sprintf("Missing annotation %q.", r.key),
+	}}
+}
+
+type systemMessages struct {
+	min parse.SystemMessageLevel
+}
+
+// SystemMessages returns a Rule reporting the system messages of the parser
+// with a level of min or above. docutils reports the messages of level
+// parse.LevelWarning and above by default. The text of a message is the text
+// of its first paragraph.
+func SystemMessages(min parse.SystemMessageLevel) Rule {
+	return systemMessages{min: min}
+}
+
+func (r systemMessages) Check(doc *rst.Document) (m []Message) {
+	if doc.Tree == nil {
+		return
+	}
+	for _, n := range doc.Messages {
+		s := n.(*parse.SystemMessageNode)
+		if s.Severity < r.min {
+			continue
+		}
+		var text string
+		if len(s.NodeList) > 0 {
+			if p, ok := s.NodeList[0].(*parse.ParagraphNode); ok {
+				text = p.Text
+			}
+		}
+		m = append(m, Message{
+			Rule:  "system-message",
+			Level: s.Severity,
+			Line:  s.Line,
+			Text:  text,
+		})
+	}
+	return
+}
@@ -0,0 +1,141 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+// bodyList is a list of body elements being checked, the root node list or
+// the node list of a section. The nodes before next are checked.
+type bodyList struct {
+	nodes *NodeList
+	next  int // Index of the first node not checked
+
+	// count is the number of nodes checked, not counting the system
+	// messages added by the checks.
+	count int
+
+	afterTransition bool // The last node checked is a transition
+	section         Node // The copy of the section given to StartNode
+}
+
+// checkBody checks the nodes of the body lists that are complete. A node is
+// complete once another node follows it in its list, or once its section is
+// closed, as the parser changes the last node of a list, for example to join
+// the lines of a paragraph. If final is true, the input is parsed and every
+// node is complete.
+//
+// The checks are the ones docutils does once the document is parsed: the
+// docinfo, the front matter, and the transitions. If the tree has an event
+// handler, the checked nodes are sent to it and dropped from the tree.
+func (t *Tree) checkBody(final bool) {
+	if !final && !t.inBody() {
+		return
+	}
+	if t.docinfo && !t.docinfoDone {
+		if !final && !t.docinfoKnown() {
+			return
+		}
+		t.docinfoDone = true
+		t.transformDocinfo()
+	}
+	if t.bodies == nil {
+		t.bodies = []*bodyList{{nodes: &t.Nodes}}
+	}
+	if t.frontMatter && t.bodies[0].count == 0 &&
+		(final || len(t.Nodes) > 1) {
+		t.findFrontMatter()
+	}
+	t.checkList(0, final, final)
+}
+
+// inBody returns true if no block quote or list is open, the parser is
+// between the body elements of the root or of a section.
+func (t *Tree) inBody() bool {
+	return len(t.quotes) == 0 && len(t.bullets) == 0 &&
+		len(t.definitions) == 0 && len(t.enums) == 0 &&
+		t.openOptionList == nil && t.openFieldList == nil
+}
+
+// checkList checks the complete nodes of t.bodies[depth]. If complete is
+// true, the last node of the list is complete. atEnd is true if nothing
+// follows the list in the document.
+func (t *Tree) checkList(depth int, complete, atEnd bool) {
+	l := t.bodies[depth]
+	for l.next < len(*l.nodes) {
+		last := l.next == len(*l.nodes)-1
+		if s, ok := (*l.nodes)[l.next].(*SectionNode); ok {
+			if !t.checkSection(depth, s, complete, atEnd && last) {
+				return
+			}
+			continue
+		}
+		if last && !complete {
+			break
+		}
+		t.checkTransition(l, atEnd && last)
+	}
+	t.sendChecked(l)
+}
+
+// checkSection checks the section s, the next node of t.bodies[depth], and
+// returns true once s is closed and checked.
+func (t *Tree) checkSection(depth int, s *SectionNode, complete,
+	atEnd bool) bool {
+	l := t.bodies[depth]
+	if len(t.bodies) == depth+1 {
+		t.sendChecked(l)
+		sl := &bodyList{nodes: &s.NodeList}
+		if t.events != nil {
+			c, children, _ := eventCopy(s)
+			sl.section = c
+			t.events.StartNode(c)
+			// The title and the adornments of s come before the
+			// nodes of its list.
+			for _, n := range children[:len(children)-len(s.NodeList)] {
+				sendEvents(t.events, n)
+			}
+		}
+		t.bodies = append(t.bodies, sl)
+	}
+	open := !complete && t.sectionOpen(s)
+	t.checkList(depth+1, !open, atEnd && !open)
+	if open {
+		return false
+	}
+	if t.events != nil {
+		t.events.EndNode(t.bodies[depth+1].section)
+		t.sentNodes += countNode(s)
+		(*l.nodes)[l.next] = nil
+		*l.nodes = (*l.nodes)[l.next+1:]
+	} else {
+		l.next++
+	}
+	t.bodies = t.bodies[:depth+1]
+	l.count++
+	l.afterTransition = false
+	return true
+}
+
+// sectionOpen returns true if nodes can still be added to the section s, the
+// section is the last section found or contains it.
+func (t *Tree) sectionOpen(s *SectionNode) bool {
+	last := t.sectionLevels.lastSectionNode
+	return last != nil && s.Level <= last.Level &&
+		t.sectionLevels.LastSectionByLevel(s.Level) == s
+}
+
+// sendChecked sends the checked nodes of l to the event handler of the tree,
+// if any, and drops them from the tree.
+func (t *Tree) sendChecked(l *bodyList) {
+	if t.events == nil {
+		return
+	}
+	nl := *l.nodes
+	for i, n := range nl[:l.next] {
+		sendEvents(t.events, n)
+		t.sentNodes += countNode(n)
+		nl[i] = nil
+	}
+	*l.nodes = nl[l.next:]
+	l.next = 0
+}
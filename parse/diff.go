@@ -0,0 +1,163 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"unicode"
+)
+
+// NodeDiff is a single difference between two parse trees found by DiffNodes
+// or DiffNodeLists.
+type NodeDiff struct {
+	// Path locates the node containing the difference, for example
+	// "document/section[2]/paragraph[0]". Nodes in a NodeList are named by
+	// their type and indexed by their position in the list. Nodes that are
+	// fields of another node, such as a section title, are named by the
+	// field.
+	Path string
+
+	// Field is the name of the node field that differs. If the NodeLists
+	// of the nodes have different lengths, Field is "len(NodeList)".
+	Field string
+
+	// A and B are the values of the field in the first and second tree.
+	A, B interface{}
+}
+
+// String implements Stringer and returns the difference as
+// "path.Field: a != b".
+func (d NodeDiff) String() string {
+	return fmt.Sprintf("%s.%s: %#v != %#v", d.Path, d.Field, d.A, d.B)
+}
+
+// DiffOption configures the comparison made by DiffNodes and DiffNodeLists.
+type DiffOption func(*differ)
+
+// IgnorePositions excludes the ID, Line, and StartPosition fields of the nodes
+// from the comparison. These fields change whenever text is added or removed
+// before a node, even if the structure of the tree is the same.
+func IgnorePositions() DiffOption {
+	return func(d *differ) { d.ignorePositions = true }
+}
+
+// differ holds the options and the differences found while comparing two
+// trees.
+type differ struct {
+	ignorePositions bool
+	diffs           []NodeDiff
+}
+
+func newDiffer(opts []DiffOption) *differ {
+	d := new(differ)
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// DiffNodes compares the node a to the node b field by field, including the
+// children of the nodes, and returns the differences found. A nil slice is
+// returned if the nodes are equal.
+func DiffNodes(a, b Node, opts ...DiffOption) []NodeDiff {
+	d := newDiffer(opts)
+	d.node(nodePathName(a), a, b)
+	return d.diffs
+}
+
+// DiffNodeLists compares two lists of nodes, such as the Nodes of two parse
+// trees, and returns the differences found. The paths of the differences
+// begin with "document".
+func DiffNodeLists(a, b NodeList, opts ...DiffOption) []NodeDiff {
+	d := newDiffer(opts)
+	d.list("document", a, b)
+	return d.diffs
+}
+
+func (d *differ) add(path, field string, a, b interface{}) {
+	d.diffs = append(d.diffs, NodeDiff{Path: path, Field: field, A: a, B: b})
+}
+
+// list compares the nodes of two NodeLists located at path.
+func (d *differ) list(path string, a, b NodeList) {
+	if len(a) != len(b) {
+		d.add(path, "len(NodeList)", len(a), len(b))
+	}
+	for i := 0; i < len(a) && i < len(b); i++ {
+		d.node(path+"/"+nodePathName(a[i])+"["+strconv.Itoa(i)+"]",
+			a[i], b[i])
+	}
+}
+
+// node compares the fields of two nodes located at path.
+func (d *differ) node(path string, a, b Node) {
+	if a == nil || b == nil {
+		if a != b {
+			d.add(path, "Node", a, b)
+		}
+		return
+	}
+	va := reflect.Indirect(reflect.ValueOf(a))
+	vb := reflect.Indirect(reflect.ValueOf(b))
+	if va.Type() != vb.Type() {
+		d.add(path, "Type", a.NodeType(), b.NodeType())
+		return
+	}
+	for i := 0; i < va.NumField(); i++ {
+		f := va.Type().Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		fa, fb := va.Field(i), vb.Field(i)
+		switch fa.Interface().(type) {
+		case ID, Line, StartPosition:
+			if d.ignorePositions {
+				continue
+			}
+		case NodeList:
+			d.list(path, fa.Interface().(NodeList),
+				fb.Interface().(NodeList))
+			continue
+		}
+		if fa.Kind() == reflect.Ptr && fa.Type().Implements(nodeInterface) {
+			if fa.IsNil() || fb.IsNil() {
+				if fa.IsNil() != fb.IsNil() {
+					d.add(path, f.Name, fa.Interface(), fb.Interface())
+				}
+				continue
+			}
+			d.node(path+"."+f.Name, fa.Interface().(Node),
+				fb.Interface().(Node))
+			continue
+		}
+		if !reflect.DeepEqual(fa.Interface(), fb.Interface()) {
+			d.add(path, f.Name, fa.Interface(), fb.Interface())
+		}
+	}
+}
+
+var nodeInterface = reflect.TypeOf((*Node)(nil)).Elem()
+
+// nodePathName returns the name of the type of n used in NodeDiff paths. The
+// name is the NodeType without the "Node" prefix and in lower case words
+// separated by underscores, for example "bullet_list_item".
+func nodePathName(n Node) string {
+	if n == nil {
+		return "nil"
+	}
+	var name []rune
+	for i, r := range n.NodeType().String()[len("Node"):] {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				name = append(name, '_')
+			}
+			r = unicode.ToLower(r)
+		}
+		name = append(name, r)
+	}
+	return string(name)
+}
@@ -0,0 +1,72 @@
+package parse
+
+import (
+	"testing"
+)
+
+func TestDiffNodeListsEqual(t *testing.T) {
+	a, _ := Parse("a", "Title\n=====\n\nParagraph.\n")
+	b, _ := Parse("b", "Title\n=====\n\nParagraph.\n")
+	if d := DiffNodeLists(a.Nodes, b.Nodes); d != nil {
+		t.Errorf("Expected no differences, got: %v", d)
+	}
+}
+
+func TestDiffNodeListsPath(t *testing.T) {
+	a, _ := Parse("a", "Title\n=====\n\nParagraph one.\n")
+	b, _ := Parse("b", "Title\n=====\n\nParagraph two.\n")
+	d := DiffNodeLists(a.Nodes, b.Nodes)
+	if len(d) != 1 {
+		t.Fatalf("Expected 1 difference, got: %v", d)
+	}
+	exp := `document/section[0]/paragraph[0].Text: "Paragraph one." != "Paragraph two."`
+	if d[0].String() != exp {
+		t.Errorf("Got: %s\n\t Expect: %s", d[0], exp)
+	}
+}
+
+func TestDiffNodeListsIgnorePositions(t *testing.T) {
+	a, _ := Parse("a", "Paragraph.\n")
+	b, _ := Parse("b", "\n\nParagraph.\n")
+	if d := DiffNodeLists(a.Nodes, b.Nodes); d == nil {
+		t.Error("Expected the lines of the paragraphs to differ")
+	}
+	if d := DiffNodeLists(a.Nodes, b.Nodes, IgnorePositions()); d != nil {
+		t.Errorf("Expected no differences, got: %v", d)
+	}
+}
+
+func TestDiffNodeListsLength(t *testing.T) {
+	a, _ := Parse("a", "One.\n\nTwo.\n")
+	b, _ := Parse("b", "One.\n")
+	d := DiffNodeLists(a.Nodes, b.Nodes)
+	if len(d) != 1 || d[0].Path != "document" || d[0].Field != "len(NodeList)" {
+		t.Fatalf("Expected a length difference, got: %v", d)
+	}
+	if d[0].A != 2 || d[0].B != 1 {
+		t.Errorf("Got: %v != %v\n\t Expect: 2 != 1", d[0].A, d[0].B)
+	}
+}
+
+func TestDiffNodesType(t *testing.T) {
+	a := &ParagraphNode{Type: NodeParagraph}
+	b := &CommentNode{Type: NodeComment}
+	d := DiffNodes(a, b)
+	if len(d) != 1 || d[0].Field != "Type" {
+		t.Fatalf("Expected a type difference, got: %v", d)
+	}
+	if d[0].Path != "paragraph" {
+		t.Errorf("Got: %q\n\t Expect: %q", d[0].Path, "paragraph")
+	}
+}
+
+func TestDiffNodesField(t *testing.T) {
+	a := &SectionNode{Type: NodeSection, Title: &TitleNode{Type: NodeTitle,
+		Text: "One"}}
+	b := &SectionNode{Type: NodeSection, Title: &TitleNode{Type: NodeTitle,
+		Text: "Two"}}
+	d := DiffNodes(a, b)
+	if len(d) != 1 || d[0].Path != "section.Title" || d[0].Field != "Text" {
+		t.Errorf("Expected a title text difference, got: %v", d)
+	}
+}
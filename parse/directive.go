@@ -0,0 +1,173 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// directiveItem is a directive found by the parser.
+type directiveItem struct {
+	name     string            // The directive name
+	argument string            // The text following "::" on the marker line
+	options  []directiveOption // The options, in the order given
+	content  string            // The content, without its indentation
+	line     Line              // The line of the directive marker
+	text     string            // The lines of the directive, unindented
+	invalid  *directiveOption  // The option being reported
+}
+
+// directiveOption is an option of a directive, such as ":number-lines: 10".
+type directiveOption struct {
+	name, value string
+}
+
+// option returns the value of the option of d named name. ok is false if the
+// option is not given.
+func (d *directiveItem) option(name string) (value string, ok bool) {
+	for _, o := range d.options {
+		if o.name == name {
+			return o.value, true
+		}
+	}
+	return "", false
+}
+
+// directiveBlock consumes the directive whose marker is i, with its arguments,
+// options, and content, and returns the node of the directive. As in docutils
+// when the name of a directive is not known, a directive that is not
+// implemented is replaced by an errorUnknownDirectiveType system message
+// containing the text of the directive. A directive that ends without a blank
+// line is followed by a warningExplicitMarkupWithUnIndent system message.
+func (t *Tree) directiveBlock(i *item) Node {
+	d := &directiveItem{line: i.Line}
+	for t.peek(1).Type != itemEOF && t.peek(1).Line == i.Line {
+		switch n := t.next(1); n.Type {
+		case itemDirectiveName:
+			d.name = n.Text
+		case itemDirectiveArgument:
+			d.argument = n.Text
+		}
+	}
+	end := i.Line
+	blank := 0
+	for {
+		if t.peek(1).Type == itemBlankLine {
+			t.next(1)
+			blank++
+		} else if t.peek(1).Type == itemSpace &&
+			isDirectiveBlock(t.peek(2)) {
+			end = t.next(1).Line
+			t.directiveLine(d, end, blank)
+			blank = 0
+		} else {
+			break
+		}
+	}
+	lines := make([]string, 0, end-i.Line+1)
+	for _, line := range t.lex.lines[i.Line-1 : end] {
+		if n := int(i.StartPosition) - 1; len(line) > n {
+			line = line[n:]
+		} else {
+			line = ""
+		}
+		lines = append(lines, line)
+	}
+	d.text = strings.Join(lines, "\n")
+	var n Node
+	switch d.name {
+	case "code", "code-block":
+		n = t.codeBlock(i, d)
+	default:
+		n = t.directiveMessage(d, errorUnknownDirectiveType)
+	}
+	if z := t.peek(1).Type; blank == 0 && z != itemEOF &&
+		z != itemCommentMark && z != itemDirective {
+		t.nodeTarget.append(n)
+		return t.systemMessage(warningExplicitMarkupWithUnIndent)
+	}
+	return n
+}
+
+// directiveLine adds the items of the line of the block of d to d. blank is
+// the number of blank lines before the line.
+func (t *Tree) directiveLine(d *directiveItem, line Line, blank int) {
+	for t.peek(1).Type != itemEOF && t.peek(1).Line == line {
+		n := t.next(1)
+		switch n.Type {
+		case itemDirectiveOptionName:
+			d.options = append(d.options,
+				directiveOption{name: n.Text})
+		case itemDirectiveOptionValue:
+			o := &d.options[len(d.options)-1]
+			if o.value != "" {
+				// The value continues on the next line.
+				o.value += "\n"
+			}
+			o.value += n.Text
+		case itemDirectiveContent:
+			if d.content != "" {
+				d.content += strings.Repeat("\n", blank+1)
+			}
+			d.content += n.Text
+		}
+	}
+}
+
+// directiveMessage returns the system message err reporting the directive d.
+func (t *Tree) directiveMessage(d *directiveItem, err parserMessage) Node {
+	t.directive = d
+	m := t.systemMessage(err)
+	t.directive = nil
+	return m
+}
+
+// isDirectiveBlock returns true if i is an option or content item of the
+// block of a directive.
+func isDirectiveBlock(i *item) bool {
+	switch i.Type {
+	case itemDirectiveOption, itemDirectiveOptionValue,
+		itemDirectiveContent:
+		return true
+	}
+	return false
+}
+
+// codeBlock returns the node of the "code" directive d, or of the
+// "code-block" directive of Sphinx, whose marker is i. The argument of the
+// directive is the language of the code, and is optional. The lines of the
+// code are numbered if the :number-lines: option is given, from the number
+// given to the option or from 1, or if the :linenos: option of Sphinx is
+// given. As in docutils, a code directive without content is replaced by an
+// errorDirectiveContentExpected system message, and a :number-lines: option
+// that is not a number by an errorDirectiveInvalidOption system message.
+func (t *Tree) codeBlock(i *item, d *directiveItem) Node {
+	if d.content == "" {
+		return t.directiveMessage(d, errorDirectiveContentExpected)
+	}
+	c := newCodeBlock(&item{
+		Text:          d.content,
+		Length:        utf8.RuneCountInString(d.content),
+		StartPosition: i.StartPosition,
+		Line:          i.Line,
+	}, d.argument, &t.id)
+	if v, ok := d.option("number-lines"); ok {
+		c.NumberLines = 1
+		if v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				d.invalid = &directiveOption{"number-lines", v}
+				return t.directiveMessage(d,
+					errorDirectiveInvalidOption)
+			}
+			c.NumberLines = n
+		}
+	} else if _, ok := d.option("linenos"); ok {
+		c.NumberLines = 1
+	}
+	return c
+}
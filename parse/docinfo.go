@@ -0,0 +1,133 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import "strings"
+
+// bibliographicFields are the registered names of the fields that are
+// promoted to bibliographic fields by WithDocinfo. Dedication and abstract,
+// which docutils turns into topics, are kept as generic fields.
+var bibliographicFields = map[string]bool{
+	"author":       true,
+	"authors":      true,
+	"organization": true,
+	"address":      true,
+	"contact":      true,
+	"version":      true,
+	"revision":     true,
+	"status":       true,
+	"date":         true,
+	"copyright":    true,
+}
+
+// WithDocinfo converts the field list at the start of the document into a
+// DocinfoNode, as docutils does. The field list must be the first element of
+// the document, or of the document title section, not counting comments. The
+// document title is a section only if it is the last top level element, as
+// the title of a document has no sibling sections.
+//
+// Fields with a registered name, such as "Author" or "date", become
+// BibliographicFieldNodes, the other fields are kept as FieldNodes in the
+// docinfo. The names are matched without regard to case. A registered name
+// used twice is reported with an errorDuplicateBibliographicField system
+// message, and the second field is kept as a FieldNode.
+func WithDocinfo() ParseOption {
+	return func(t *Tree) { t.docinfo = true }
+}
+
+// transformDocinfo converts the field list found by docinfoTarget.
+func (t *Tree) transformDocinfo() {
+	nl, index := t.docinfoTarget()
+	if nl == nil {
+		return
+	}
+	fl := (*nl)[index].(*FieldListNode)
+	d := &DocinfoNode{ID: fl.ID, Type: NodeDocinfo, Line: fl.Line}
+	var messages NodeList
+	seen := make(map[string]bool)
+	for _, n := range fl.NodeList {
+		f := n.(*FieldNode)
+		name := strings.ToLower(strings.Join(strings.Fields(f.Name.Text),
+			" "))
+		switch {
+		case seen[name]:
+			m := t.systemMessage(errorDuplicateBibliographicField)
+			m.(*SystemMessageNode).Line = f.Line
+			messages.append(m)
+		case bibliographicFields[name]:
+			seen[name] = true
+			d.NodeList.append(&BibliographicFieldNode{
+				ID:    f.ID,
+				Type:  NodeBibliographicField,
+				Line:  f.Line,
+				Field: name,
+				Name:  f.Name,
+				Body:  f.Body,
+			})
+			continue
+		}
+		d.NodeList.append(f)
+	}
+	rest := append(NodeList{d}, messages...)
+	*nl = append((*nl)[:index], append(rest, (*nl)[index+1:]...)...)
+}
+
+// docinfoTarget returns the list containing the field list converted by
+// WithDocinfo and the index of the field list in it, or nil if the document
+// does not begin with a field list.
+func (t *Tree) docinfoTarget() (nl *NodeList, index int) {
+	nl = &t.Nodes
+	index = firstNonComment(*nl)
+	if index == len(*nl) {
+		return nil, 0
+	}
+	if s, ok := (*nl)[index].(*SectionNode); ok && index == len(*nl)-1 {
+		nl = &s.NodeList
+		index = firstNonComment(*nl)
+		if index == len(*nl) {
+			return nil, 0
+		}
+	}
+	if _, ok := (*nl)[index].(*FieldListNode); !ok {
+		return nil, 0
+	}
+	return
+}
+
+// docinfoKnown returns true if the nodes that docinfoTarget looks at are
+// complete, and so the field list it finds, if any, is known before the
+// input is parsed.
+func (t *Tree) docinfoKnown() bool {
+	index := firstNonComment(t.Nodes)
+	switch {
+	case index == len(t.Nodes):
+		return false
+	case index < len(t.Nodes)-1:
+		return true
+	}
+	s, ok := t.Nodes[index].(*SectionNode)
+	if !ok {
+		return false
+	}
+	// The section is the document title as long as nothing follows it,
+	// its first element decides only if it is not a field list.
+	index = firstNonComment(s.NodeList)
+	if index >= len(s.NodeList)-1 {
+		return false
+	}
+	_, ok = s.NodeList[index].(*FieldListNode)
+	return !ok
+}
+
+// firstNonComment returns the index of the first node of nl that is not a
+// comment, or len(nl) if there is none.
+func firstNonComment(nl NodeList) int {
+	for i, n := range nl {
+		if _, ok := n.(*CommentNode); !ok {
+			return i
+		}
+	}
+	return len(nl)
+}
@@ -0,0 +1,207 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Position is the location of text in the input.
+type Position struct {
+	Line
+	StartPosition
+}
+
+// EventHandler receives the parse tree of a document as a sequence of events,
+// in document order.
+//
+// The events of a node are StartNode, Text if the node has text, the events of
+// the nodes it contains, and EndNode. The node given to StartNode and EndNode
+// is a copy without its text and without the nodes it contains, which are
+// given by the events between them. A field of the copy that holds a single
+// node, such as the title of a section, holds a node with only its ID and
+// Type. System messages are given to Message whole, with no other events.
+type EventHandler interface {
+	StartNode(n Node)
+	EndNode(n Node)
+	Text(text string, pos Position)
+	Message(m *SystemMessageNode)
+}
+
+// ParseEvents parses text and sends the parse tree to h. opts are the options
+// of Parse. The events of a node are sent once the parser has left it, when
+// the next element of its section is parsed or the section is closed, and
+// the node is then dropped from the tree. The events are held while the
+// parser is in a block quote or a list, and, with WithDocinfo, until the field
+// list converted to the docinfo is known.
+//
+// System messages are sent to h.Message and do not cause an error. The error
+// returned is the error that stopped parsing, if any, the events of the
+// input read until then are sent.
+func ParseEvents(name, text string, h EventHandler,
+	opts ...ParseOption) error {
+	opts = append(opts[:len(opts):len(opts)], func(t *Tree) {
+		t.events = h
+	})
+	t, _ := Parse(name, text, opts...)
+	return t.err
+}
+
+// sendEvents sends the events of n and of the nodes it contains to h.
+func sendEvents(h EventHandler, n Node) {
+	if m, ok := n.(*SystemMessageNode); ok {
+		h.Message(m)
+		return
+	}
+	c, children, text := eventCopy(n)
+	h.StartNode(c)
+	if text != "" {
+		h.Text(text, position(reflect.ValueOf(n).Elem()))
+	}
+	for _, child := range children {
+		sendEvents(h, child)
+	}
+	h.EndNode(c)
+}
+
+// eventCopy returns the copy of n given to StartNode and EndNode, the nodes
+// contained in n, in the order of the fields of n, and the text of n.
+func eventCopy(n Node) (c Node, children []Node, text string) {
+	v := reflect.ValueOf(n).Elem()
+	cv := reflect.New(v.Type())
+	cv.Elem().Set(v)
+	for i := 0; i < v.NumField(); i++ {
+		f := cv.Elem().Field(i)
+		switch {
+		case v.Type().Field(i).PkgPath != "":
+		case f.Type() == nodeListType:
+			children = append(children, f.Interface().(NodeList)...)
+			f.Set(reflect.Zero(f.Type()))
+		case f.Kind() == reflect.Ptr && f.Type().Implements(nodeInterface):
+			if f.IsNil() {
+				continue
+			}
+			children = append(children, f.Interface().(Node))
+			stub := reflect.New(f.Type().Elem())
+			for _, name := range []string{"ID", "Type"} {
+				stub.Elem().FieldByName(name).Set(
+					f.Elem().FieldByName(name))
+			}
+			f.Set(stub)
+		}
+	}
+	if f := cv.Elem().FieldByName("Text"); f.IsValid() {
+		text = f.String()
+		f.SetString("")
+	}
+	return cv.Interface().(Node), children, text
+}
+
+var nodeListType = reflect.TypeOf(NodeList(nil))
+
+// position returns the Line and StartPosition fields of the node v.
+func position(v reflect.Value) (p Position) {
+	if f := v.FieldByName("Line"); f.IsValid() {
+		p.Line = f.Interface().(Line)
+	}
+	if f := v.FieldByName("StartPosition"); f.IsValid() {
+		p.StartPosition = f.Interface().(StartPosition)
+	}
+	return
+}
+
+// Builder is an EventHandler that builds the parse tree from the events of
+// ParseEvents. The tree is the same as the tree returned by Parse. Events
+// that do not fit the tree being built, such as a node sent to a node that
+// cannot contain it, are dropped and reported by Err.
+type Builder struct {
+	Nodes NodeList // The root node list
+	open  []reflect.Value
+	err   error
+}
+
+// Err returns the error reporting the first event that was dropped, or nil.
+func (b *Builder) Err() error {
+	return b.err
+}
+
+// fail records the error of a dropped event, unless one is recorded.
+func (b *Builder) fail(format string, args ...interface{}) {
+	if b.err == nil {
+		b.err = fmt.Errorf("parse: "+format, args...)
+	}
+}
+
+// StartNode opens n, the copy of a node given by ParseEvents.
+func (b *Builder) StartNode(n Node) {
+	b.open = append(b.open, reflect.ValueOf(n).Elem())
+}
+
+// EndNode adds the innermost open node to its parent.
+func (b *Builder) EndNode(n Node) {
+	if len(b.open) == 0 {
+		b.fail("no open node to end with %s", n.NodeType())
+		return
+	}
+	c := b.open[len(b.open)-1]
+	b.open = b.open[:len(b.open)-1]
+	b.add(c.Addr().Interface().(Node))
+}
+
+// Text sets the text of the innermost open node.
+func (b *Builder) Text(text string, pos Position) {
+	if len(b.open) == 0 {
+		b.fail("text at line %d is not in a node", pos.Line)
+		return
+	}
+	f := b.open[len(b.open)-1].FieldByName("Text")
+	if !f.IsValid() {
+		b.fail("text at line %d is in a node without text", pos.Line)
+		return
+	}
+	f.SetString(text)
+}
+
+// Message adds m to the innermost open node.
+func (b *Builder) Message(m *SystemMessageNode) {
+	b.add(m)
+}
+
+// add adds n to the innermost open node, or to the root node list. n replaces
+// the node with the same ID held by a field of the open node, or else is
+// appended to the node list of the open node. n is dropped if the open node
+// has no node list.
+func (b *Builder) add(n Node) {
+	if len(b.open) == 0 {
+		b.Nodes.append(n)
+		return
+	}
+	p := b.open[len(b.open)-1]
+	nv := reflect.ValueOf(n)
+	id := nv.Elem().FieldByName("ID").Interface()
+	list := -1
+	for i := 0; i < p.NumField(); i++ {
+		f := p.Field(i)
+		if p.Type().Field(i).PkgPath != "" {
+			continue
+		}
+		if f.Type() == nv.Type() && !f.IsNil() &&
+			f.Elem().FieldByName("ID").Interface() == id {
+			f.Set(nv)
+			return
+		}
+		if f.Type() == nodeListType && list == -1 {
+			list = i
+		}
+	}
+	if list == -1 {
+		b.fail("%s %d cannot be added to %s", n.NodeType(), id,
+			p.Addr().Interface().(Node).NodeType())
+		return
+	}
+	f := p.Field(list)
+	f.Set(reflect.Append(f, nv))
+}
@@ -0,0 +1,88 @@
+package parse
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// sectionIndexer is an EventHandler that prints the text of each paragraph
+// with the titles of the sections containing it. Only the open section titles
+// are kept, not the parse tree.
+type sectionIndexer struct {
+	path    []string
+	inTitle bool
+	inPara  bool
+}
+
+func (x *sectionIndexer) StartNode(n Node) {
+	switch n.(type) {
+	case *SectionNode:
+		x.path = append(x.path, "")
+	case *TitleNode:
+		x.inTitle = true
+	case *ParagraphNode:
+		x.inPara = true
+	}
+}
+
+func (x *sectionIndexer) EndNode(n Node) {
+	switch n.(type) {
+	case *SectionNode:
+		x.path = x.path[:len(x.path)-1]
+	case *TitleNode:
+		x.inTitle = false
+	case *ParagraphNode:
+		x.inPara = false
+	}
+}
+
+func (x *sectionIndexer) Text(text string, pos Position) {
+	switch {
+	case x.inTitle:
+		x.path[len(x.path)-1] = text
+	case x.inPara:
+		fmt.Printf("%s: %s\n", strings.Join(x.path, " > "),
+			strings.Replace(text, "\n", " ", -1))
+	}
+}
+
+func (x *sectionIndexer) Message(m *SystemMessageNode) {}
+
+func ExampleParseEvents() {
+	text := `Guide
+=====
+
+Read this first.
+
+Install
+-------
+
+Run the installer.
+
+Usage
+-----
+
+Run the command
+with a file.`
+	ParseEvents("guide", text, &sectionIndexer{})
+	// Output:
+	// Guide: Read this first.
+	// Guide > Install: Run the installer.
+	// Guide > Usage: Run the command with a file.
+}
+
+func TestBuilderDropsMisplacedNode(t *testing.T) {
+	// A transition has no node list, the message sent in it is dropped.
+	var b Builder
+	tr := &TransitionNode{ID: 1, Type: NodeTransition}
+	b.StartNode(tr)
+	b.Message(&SystemMessageNode{ID: 2, Type: NodeSystemMessage})
+	b.EndNode(tr)
+	if b.Err() == nil {
+		t.Error("Err() == nil, want the dropped message reported")
+	}
+	if len(b.Nodes) != 1 {
+		t.Errorf("len(Nodes) == %d, want 1", len(b.Nodes))
+	}
+}
@@ -0,0 +1,58 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import (
+	"reflect"
+
+	"github.com/demizer/go-rst/internal/expect"
+)
+
+// expectTypes are the node types given to expect.Decode to decode the
+// expected nodes of the test fixtures.
+var expectTypes = func() expect.Types {
+	types := make(expect.Types, len(nodeStructs))
+	for t, s := range nodeStructs {
+		types[t.String()] = s
+	}
+	return types
+}()
+
+// nodeStructs maps each NodeType to the struct used by the parser for nodes of
+// that type.
+var nodeStructs = map[NodeType]reflect.Type{
+	NodeSection:            reflect.TypeOf(SectionNode{}),
+	NodeParagraph:          reflect.TypeOf(ParagraphNode{}),
+	NodeAdornment:          reflect.TypeOf(AdornmentNode{}),
+	NodeBlockQuote:         reflect.TypeOf(BlockQuoteNode{}),
+	NodeSystemMessage:      reflect.TypeOf(SystemMessageNode{}),
+	NodeLiteralBlock:       reflect.TypeOf(LiteralBlockNode{}),
+	NodeTransition:         reflect.TypeOf(TransitionNode{}),
+	NodeTitle:              reflect.TypeOf(TitleNode{}),
+	NodeComment:            reflect.TypeOf(CommentNode{}),
+	NodeBulletList:         reflect.TypeOf(BulletListNode{}),
+	NodeBulletListItem:     reflect.TypeOf(BulletListItemNode{}),
+	NodeEnumList:           reflect.TypeOf(EnumListNode{}),
+	NodeDefinitionList:     reflect.TypeOf(DefinitionListNode{}),
+	NodeDefinitionListItem: reflect.TypeOf(DefinitionListItemNode{}),
+	NodeDefinitionTerm:     reflect.TypeOf(DefinitionTermNode{}),
+	NodeDefinition:         reflect.TypeOf(DefinitionNode{}),
+	NodeAttribution:        reflect.TypeOf(AttributionNode{}),
+	NodeEnumListItem:       reflect.TypeOf(EnumListItemNode{}),
+	NodeClassifier:         reflect.TypeOf(ClassifierNode{}),
+	NodeFieldList:          reflect.TypeOf(FieldListNode{}),
+	NodeField:              reflect.TypeOf(FieldNode{}),
+	NodeFieldName:          reflect.TypeOf(FieldNameNode{}),
+	NodeFieldBody:          reflect.TypeOf(FieldBodyNode{}),
+	NodeDocinfo:            reflect.TypeOf(DocinfoNode{}),
+	NodeBibliographicField: reflect.TypeOf(BibliographicFieldNode{}),
+	NodeOptionList:         reflect.TypeOf(OptionListNode{}),
+	NodeOptionListItem:     reflect.TypeOf(OptionListItemNode{}),
+	NodeOption:             reflect.TypeOf(OptionNode{}),
+	NodeOptionArgument:     reflect.TypeOf(OptionArgumentNode{}),
+	NodeDescription:        reflect.TypeOf(DescriptionNode{}),
+	NodeDoctestBlock:       reflect.TypeOf(DoctestBlockNode{}),
+	NodeCodeBlock:          reflect.TypeOf(CodeBlockNode{}),
+}
@@ -0,0 +1,61 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import "strings"
+
+// FrontMatter is the comment at the start of a document, such as a license
+// header, found by WithFrontMatterComment.
+type FrontMatter struct {
+	// Text is the input lines of the comment, including the comment
+	// marker and the indentation of the comment body.
+	Text string
+
+	// Line and EndLine are the first and last line of the comment in the
+	// input.
+	Line
+	EndLine Line
+
+	// Comment is the parsed comment.
+	Comment *CommentNode
+}
+
+// WithFrontMatterComment records the comment that begins the document, before
+// the document title and any other element, as the FrontMatter of the tree.
+// The comment is kept in the parse tree, FrontMatter.Comment tells it apart
+// from the other comments. Like all comments, it is not written by the
+// writers and is not counted by the document statistics.
+func WithFrontMatterComment() ParseOption {
+	return func(t *Tree) { t.frontMatter = true }
+}
+
+// findFrontMatter sets the FrontMatter of the tree if the first node of the
+// document is a comment.
+func (t *Tree) findFrontMatter() {
+	if len(t.Nodes) == 0 {
+		return
+	}
+	c, ok := t.Nodes[0].(*CommentNode)
+	if !ok {
+		return
+	}
+	// The comment marker is on the first line that is not blank, the text
+	// of the comment can begin on the following line.
+	start := 0
+	for start < len(t.lex.lines)-1 &&
+		strings.TrimSpace(t.lex.lines[start]) == "" {
+		start++
+	}
+	end := int(c.Line) + strings.Count(c.Text, "\n")
+	if end < start+1 {
+		end = start + 1
+	}
+	t.FrontMatter = &FrontMatter{
+		Text:    strings.Join(t.lex.lines[start:end], "\n"),
+		Line:    Line(start + 1),
+		EndLine: Line(end),
+		Comment: c,
+	}
+}
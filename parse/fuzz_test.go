@@ -0,0 +1,72 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/demizer/go-rst/internal/corpus"
+)
+
+// parseTimeout is the time allowed to parse one fuzz input. A parser loop that
+// does not read the token buffer is not stopped by maxStalledStates, it is
+// found by the timeout instead.
+const parseTimeout = 5 * time.Second
+
+// addCorpusSeeds adds the input of every fixture to the seed corpus of f, with
+// and without the final newline.
+func addCorpusSeeds(f *testing.F) {
+	paths, err := fs.Glob(corpus.Files, "*/*/*.rst")
+	if err != nil {
+		f.Fatal(err)
+	}
+	for _, p := range paths {
+		data, err := corpus.Files.ReadFile(p)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(string(data))
+		f.Add(strings.TrimRight(string(data), "\n"))
+	}
+}
+
+func FuzzLex(f *testing.F) {
+	addCorpusSeeds(f)
+	f.Fuzz(func(t *testing.T, input string) {
+		if !utf8.ValidString(input) {
+			// checkItemSpans compares the item text to the input.
+			t.Skip()
+		}
+		items := lexAll(input)
+		if i := items[len(items)-1]; i.Type == itemError {
+			t.Fatalf("%q: %s", input, i.Text)
+		}
+		checkItemSpans(t, "fuzz", input, items)
+	})
+}
+
+func FuzzParse(f *testing.F) {
+	addCorpusSeeds(f)
+	f.Fuzz(func(t *testing.T, input string) {
+		done := make(chan *Tree, 1)
+		go func() {
+			tr, _ := Parse("fuzz", input)
+			done <- tr
+		}()
+		select {
+		case tr := <-done:
+			if tr.err != nil {
+				t.Fatalf("%q: %s", input, tr.err)
+			}
+		case <-time.After(parseTimeout):
+			t.Fatalf("%q: parsing did not finish in %s", input,
+				parseTimeout)
+		}
+	})
+}
@@ -5,6 +5,8 @@
 package parse
 
 import (
+	"encoding/json"
+	"fmt"
 	"strconv"
 	"strings"
 	"unicode"
@@ -12,7 +14,7 @@ import (
 
 	"github.com/demizer/go-elog"
 
-	"code.google.com/p/go.text/unicode/norm"
+	"golang.org/x/text/unicode/norm"
 )
 
 // ID is a consecutive number for identication of a lexed item and parsed item.
@@ -45,7 +47,9 @@ func (s StartPosition) Position() StartPosition { return s }
 // String implements Stringer and returns StartPosition converted to a string.
 func (s StartPosition) String() string { return strconv.Itoa(int(s)) }
 
-// itemElement are the types that are emitted by the lexer.
+// itemElement are the types that are emitted by the lexer. New elements must
+// be appended to the end of the list, the numbers of the existing elements
+// must not change.
 type itemElement int
 
 const (
@@ -67,6 +71,27 @@ const (
 	itemInlineLiteral
 	itemDefinitionTerm
 	itemBullet
+	itemEnumListAlpha
+	itemEnumListRoman
+	itemEnumListAuto
+	itemClassifierDelimiter
+	itemClassifier
+	itemFieldList
+	itemFieldName
+	itemFieldBody
+	itemOptionList
+	itemOption
+	itemOptionArgument
+	itemOptionDescription
+	itemDoctestBlock
+	itemDirective
+	itemDirectiveName
+	itemDirectiveArgument
+	itemDirectiveOption
+	itemDirectiveOptionName
+	itemDirectiveOptionValue
+	itemDirectiveContent
+	itemAttribution
 )
 
 var elements = [...]string{
@@ -88,20 +113,69 @@ var elements = [...]string{
 	"itemInlineLiteral",
 	"itemDefinitionTerm",
 	"itemBullet",
+	"itemEnumListAlpha",
+	"itemEnumListRoman",
+	"itemEnumListAuto",
+	"itemClassifierDelimiter",
+	"itemClassifier",
+	"itemFieldList",
+	"itemFieldName",
+	"itemFieldBody",
+	"itemOptionList",
+	"itemOption",
+	"itemOptionArgument",
+	"itemOptionDescription",
+	"itemDoctestBlock",
+	"itemDirective",
+	"itemDirectiveName",
+	"itemDirectiveArgument",
+	"itemDirectiveOption",
+	"itemDirectiveOptionName",
+	"itemDirectiveOptionValue",
+	"itemDirectiveContent",
+	"itemAttribution",
 }
 
 // String implements the Stringer interface for printing itemElement types.
 func (t itemElement) String() string { return elements[t] }
 
+// MarshalJSON implements json.Marshaler. The element is encoded by its name,
+// such as "itemParagraph", so that encoded items do not depend on the numbers
+// of the elements.
+func (t itemElement) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler. The element can be given by its
+// name or by its number.
 func (t *itemElement) UnmarshalJSON(data []byte) error {
-	for num, elm := range elements {
-		if elm == string(data[1:len(data)-1]) {
-			*t = itemElement(num)
-		}
+	num, err := unmarshalName(data, elements[:])
+	if err != nil {
+		return fmt.Errorf("itemElement: %s", err)
 	}
+	*t = itemElement(num)
 	return nil
 }
 
+// unmarshalName returns the index into names of the JSON encoded name or
+// number in data.
+func unmarshalName(data []byte, names []string) (int, error) {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		num, err := strconv.Atoi(string(data))
+		if err != nil || num < 0 || num >= len(names) {
+			return 0, fmt.Errorf("invalid value %s", data)
+		}
+		return num, nil
+	}
+	for num, n := range names {
+		if n == name {
+			return num, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown name %q", name)
+}
+
 // Valid section adornment runes
 var sectionAdornments = []rune{'!', '"', '#', '$', '\'', '%', '&', '(', ')',
 	'*', '+', ',', '-', '.', '/', ':', ';', '<', '=', '>', '?', '@', '[',
@@ -125,35 +199,98 @@ type item struct {
 	Length        int `json:"length"`
 }
 
+// maxLogExcerpt is the number of runes of the item text included in log
+// output. Items can be very large, such as a literal block of a generated
+// file, so only the start is logged.
+const maxLogExcerpt = 120
+
+// logString returns a one line description of the item for log output.
+func (i *item) logString() string {
+	text, n := "", 0
+	for pos := range i.Text {
+		if n == maxLogExcerpt {
+			text = fmt.Sprintf("%q...(+%d bytes)", i.Text[:pos],
+				len(i.Text)-pos)
+			break
+		}
+		n++
+	}
+	if text == "" {
+		text = strconv.Quote(i.Text)
+	}
+	return fmt.Sprintf("%s ID=%d line %d position %d: %s", i.Type, i.ID,
+		i.Line, i.StartPosition, text)
+}
+
+// infoLogEnabled reports whether info messages are written to the log. The
+// go-elog functions format their arguments before checking the level, so the
+// check avoids formatting items when logging is off.
+func infoLogEnabled() bool {
+	lvl := log.Level()
+	return lvl == log.LEVEL_PRINT || lvl <= log.LEVEL_INFO
+}
+
+// debugLogEnabled reports whether debug messages are written to the log.
+func debugLogEnabled() bool {
+	lvl := log.Level()
+	return lvl == log.LEVEL_PRINT || lvl <= log.LEVEL_DEBUG
+}
+
 // The lexer struct tracks the state of the lexer
 type lexer struct {
-	name             string    // The name of the current lexer
-	input            string    // The input text
-	line             int       // Line number of the parser, from 0
-	lines            []string  // The input split into lines
-	state            stateFn   // The current state of the lexer
-	start            int       // Start position of the token in the line
-	index            int       // Position in input
-	width            int       // The width of the current position
-	items            chan item // The channel items are emitted to
-	lastItem         *item     // The last item emitted to the channel
+	name             string   // The name of the current lexer
+	input            string   // The input text
+	line             int      // Line number of the parser, from 0
+	lines            []string // The input split into lines
+	state            stateFn  // The next state, nil once lexing ends
+	start            int      // Start position of the token in the line
+	index            int      // Position in input
+	width            int      // The width of the current position
+	items            []item   // Emitted items not yet returned
+	lastItem         *item    // The last item emitted
+	lastParagraph    *item    // The last line of paragraph text emitted
+	lastAdornment    *item    // The last section adornment emitted
+	paragraphLines   int      // The lines of text ending at lastParagraph
 	lastItemPosition StartPosition
 	id               int    // Unique ID for each item emitted
 	mark             rune   // The current lexed rune
 	indentLevel      int    // For tracking indentation with indentable items
 	indentWidth      string // For tracking indent width
+	nbspIndent       bool   // Treat U+00A0 as a space in indentation
+
+	// The lines of the last attribution found by isAttribution, from 0,
+	// and the indent of its first line.
+	attributionStart  int
+	attributionEnd    int
+	attributionIndent int
+
+	// The position of the lexer when it last made progress, and the
+	// number of states run since then. Checked by step.
+	progress lexerProgress
+	stalled  int
 }
 
-func newLexer(name, input string) *lexer {
-	if len(input) == 0 {
-		return nil
-	}
+// whitespaceReplacer converts the whitespace characters that docutils reads
+// as spaces.
+var whitespaceReplacer = strings.NewReplacer("\v", " ", "\f", " ")
 
+// newLexer returns a lexer for input. As in docutils, the spaces and tabs at
+// the end of each line are removed, so no item ends with whitespace and a
+// line of whitespace is empty. A carriage return at the end of a line is
+// removed too, so CRLF line endings are read as LF, and vertical tabs and form
+// feeds are read as spaces. If raw is true, the lines are kept as written.
+func newLexer(name, input string, raw bool) *lexer {
 	if !norm.NFC.IsNormalString(input) {
 		input = norm.NFC.String(input)
 	}
 
 	lines := strings.Split(input, "\n")
+	if !raw {
+		for i, line := range lines {
+			line = whitespaceReplacer.Replace(line)
+			lines[i] = strings.TrimRight(line, " \t\r")
+		}
+	}
 
 	mark, width := utf8.DecodeRuneInString(lines[0][0:])
 
@@ -163,7 +300,7 @@ func newLexer(name, input string) *lexer {
 		name:  name,
 		input: input,
 		lines: lines,
-		items: make(chan item),
+		state: lexStart,
 		index: 0,
 		mark:  mark,
 		width: width,
@@ -174,21 +311,54 @@ func newLexer(name, input string) *lexer {
 // the purporse of the lexer. It is mostly used to identify the lexing process
 // in debugging.
 func lex(name, input string) *lexer {
-	l := newLexer(name, input)
-	if l == nil {
-		return nil
-	}
-	go l.run()
-	return l
+	return newLexer(name, input, false)
+}
+
+// maxStalledStates is the number of consecutive state transitions that may
+// neither consume input nor emit an item. If it is exceeded, the lexer emits
+// an itemError and stops, instead of looping forever. The parser stops the
+// same way when its loop reads the same item more than maxStalledStates times
+// in a row. Zero disables the check. The tests enable it so that a state
+// function that does not make progress fails the test that found it.
+var maxStalledStates = 0
+
+// lexerProgress is the position of the lexer and the number of items emitted,
+// which change when the lexer makes progress.
+type lexerProgress struct {
+	line, index, id int
 }
 
-// run is the engine of the lexing process.
-func (l *lexer) run() {
-	log.Debugln("Start")
-	for l.state = lexStart; l.state != nil; {
-		l.state = l.state(l)
+// step is the engine of the lexing process. It runs the current state function
+// of the lexer, which emits any number of items, and moves to the next state.
+// The lexer runs in the goroutine of its caller, step is called by nextItem
+// when no emitted item is waiting, so lexing never blocks and only runs as far
+// ahead of the parser as the parser reads.
+func (l *lexer) step() {
+	l.state = l.state(l)
+	if maxStalledStates == 0 || l.state == nil {
+		return
+	}
+	if p := (lexerProgress{l.line, l.index, l.id}); p != l.progress {
+		l.progress, l.stalled = p, 0
+	} else if l.stalled++; l.stalled > maxStalledStates {
+		l.errorf("lexer made no progress at line %d", l.lineNumber())
 	}
-	log.Debugln("End")
+}
+
+// errorf emits an itemError with the formatted text at the current position
+// and ends the lexing of the input.
+func (l *lexer) errorf(format string, args ...interface{}) {
+	l.id++
+	text := fmt.Sprintf(format, args...)
+	l.items = append(l.items, item{
+		ID:            ID(l.id),
+		Type:          itemError,
+		Text:          text,
+		Line:          Line(l.lineNumber()),
+		StartPosition: StartPosition(l.index + 1),
+		Length:        utf8.RuneCountInString(text),
+	})
+	l.state = nil
 }
 
 // emit passes an item back to the client.
@@ -203,9 +373,6 @@ func (l *lexer) emit(t itemElement) {
 		tok = l.lines[l.line][l.start:l.index]
 	}
 
-	log.Infof("%s: %q l.start: %d (%d) l.index: %d (%d) line: %d\n", t,
-		tok, l.start, l.start+1, l.index, l.index+1, l.lineNumber())
-
 	l.id++
 	length := utf8.RuneCountInString(tok)
 
@@ -219,8 +386,25 @@ func (l *lexer) emit(t itemElement) {
 		Length:        length,
 	}
 
-	l.items <- nItem
+	if infoLogEnabled() {
+		log.Infof("%s l.start: %d l.index: %d\n", nItem.logString(),
+			l.start, l.index)
+	}
+
+	l.items = append(l.items, nItem)
 	l.lastItem = &nItem
+	if t == itemParagraph || t == itemBlockQuote {
+		if p := l.lastParagraph; p != nil && p.Line == nItem.Line-1 &&
+			p.StartPosition == nItem.StartPosition {
+			l.paragraphLines++
+		} else {
+			l.paragraphLines = 1
+		}
+		l.lastParagraph = &nItem
+	}
+	if t == itemSectionAdornment {
+		l.lastAdornment = &nItem
+	}
 	l.start = l.index
 }
 
@@ -257,8 +441,13 @@ func (l *lexer) backup(pos int) {
 
 // peek looks ahead in the input by one position and returns the rune.
 func (l *lexer) peek() (r rune) {
+	// The position is restored instead of backing up, because the width
+	// of the last rune of a line is lost when next reaches the end of it.
+	line, start, index, mark, width := l.line, l.start, l.index, l.mark,
+		l.width
 	r, _ = l.next()
-	l.backup(1)
+	l.line, l.start, l.index, l.mark, l.width = line, start, index, mark,
+		width
 	return
 }
 
@@ -281,8 +470,10 @@ func (l *lexer) next() (r rune, width int) {
 	l.width = width
 	l.mark = r
 
-	log.Debugf("mark: %#U, start: %d, index: %d, line: %d\n",
-		r, l.start, l.index, l.lineNumber())
+	if debugLogEnabled() {
+		log.Debugf("mark: %#U, start: %d, index: %d, line: %d\n",
+			r, l.start, l.index, l.lineNumber())
+	}
 
 	return
 }
@@ -298,15 +489,22 @@ func (l *lexer) nextLine() string {
 	return l.lines[l.line]
 }
 
-// nextItem returns the next item from the input.
+// nextItem returns the next item from the input, running the lexer until an
+// item is emitted. nil is returned once the lexing of the input has ended and
+// every item has been returned.
 func (l *lexer) nextItem() *item {
-	item, ok := <-l.items
-	if ok == false {
-		return nil
+	for len(l.items) == 0 {
+		if l.state == nil {
+			return nil
+		}
+		l.step()
 	}
+	item := l.items[0]
+	// The items are moved down so the queue reuses its memory.
+	n := copy(l.items, l.items[1:])
+	l.items = l.items[:n]
 	l.lastItemPosition = item.StartPosition
 	return &item
-
 }
 
 // gotoLine advances the lexer to a line and index within that line. Line
@@ -336,11 +534,18 @@ func (l *lexer) lastLineIsBlankLine() bool {
 	if l.line == 0 {
 		return false
 	}
-	m, _ := utf8.DecodeRuneInString(l.lines[l.line-1])
-	if m == utf8.RuneError {
-		return true
-	}
-	return false
+	return strings.TrimFunc(l.lines[l.line-1], l.isIndentSpace) == ""
+}
+
+// isBlankLine reports whether line n, from 0, contains only whitespace.
+func (l *lexer) isBlankLine(n int) bool {
+	return strings.TrimFunc(l.lines[n], l.isIndentSpace) == ""
+}
+
+// isWhitespaceLine reports whether the current line contains only
+// whitespace.
+func (l *lexer) isWhitespaceLine() bool {
+	return strings.TrimFunc(l.currentLine(), l.isIndentSpace) == ""
 }
 
 func (l *lexer) isEndOfLine() bool {
@@ -352,9 +557,139 @@ func isSpace(r rune) bool {
 	return r == ' ' || r == '\t' || r == '\n' || r == '\r'
 }
 
+// isIndentSpace reports whether r is a space character in the indentation of
+// a line. The no-break space (U+00A0) is only indentation if the lexer has
+// nbspIndent set.
+func (l *lexer) isIndentSpace(r rune) bool {
+	return isSpace(r) || (l.nbspIndent && r == '\u00a0')
+}
+
 // isArabic returns true if rune r is an Arabic numeral.
 func isArabic(r rune) bool {
-	return r > '0' && r < '9'
+	return r >= '0' && r <= '9'
+}
+
+// enumerator is the enumerator of an enumerated list item, such as "2.",
+// "(a)", or "iv)". text is the enumerator without its affixes, and seq is the
+// itemElement of its sequence.
+type enumerator struct {
+	prefix string
+	text   string
+	suffix string
+	seq    itemElement
+}
+
+// scanEnumerator returns the enumerator at the start of s, which must be
+// followed by a space or the end of s. The formats are those of docutils:
+// "1.", "1)", and "(1)".
+func scanEnumerator(s string) (e enumerator, ok bool) {
+	if strings.HasPrefix(s, "(") {
+		e.prefix, s = "(", s[1:]
+	}
+	end := strings.IndexAny(s, ".)")
+	if end < 1 {
+		return e, false
+	}
+	e.text, e.suffix = s[:end], s[end:end+1]
+	if e.prefix == "(" && e.suffix != ")" {
+		return e, false
+	}
+	if rest := s[end+1:]; rest != "" && rest[0] != ' ' {
+		return e, false
+	}
+	e.seq, ok = enumSequence(e.text)
+	return
+}
+
+// enumSequence returns the sequence of the enumerator text. As in docutils,
+// "i" and "I" are roman numerals and other single letters are alphabetic. The
+// parser decides the sequence of ambiguous enumerators, such as the "i" that
+// follows "h", from the enumerators before them.
+func enumSequence(text string) (itemElement, bool) {
+	switch {
+	case text == "#":
+		return itemEnumListAuto, true
+	case strings.IndexFunc(text, func(r rune) bool {
+		return !isArabic(r)
+	}) == -1:
+		return itemEnumListArabic, true
+	case text == "i" || text == "I":
+		return itemEnumListRoman, true
+	case len(text) == 1 && (text[0] >= 'a' && text[0] <= 'z' ||
+		text[0] >= 'A' && text[0] <= 'Z'):
+		return itemEnumListAlpha, true
+	case romanValue(text) > 0:
+		return itemEnumListRoman, true
+	}
+	return itemEOF, false
+}
+
+// next returns the enumerator that follows e in its sequence, or "" if there
+// is none.
+func (e enumerator) next() string {
+	var text string
+	switch e.seq {
+	case itemEnumListArabic:
+		n, err := strconv.Atoi(e.text)
+		if err != nil {
+			return ""
+		}
+		text = strconv.Itoa(n + 1)
+	case itemEnumListAlpha:
+		if e.text == "z" || e.text == "Z" {
+			return ""
+		}
+		text = string(e.text[0] + 1)
+	case itemEnumListRoman:
+		text = toRoman(romanValue(e.text) + 1)
+		if e.text == strings.ToLower(e.text) {
+			text = strings.ToLower(text)
+		}
+	case itemEnumListAuto:
+		text = "#"
+	}
+	return e.prefix + text + e.suffix
+}
+
+var romanNumerals = []struct {
+	value   int
+	numeral string
+}{
+	{1000, "M"}, {900, "CM"}, {500, "D"}, {400, "CD"}, {100, "C"},
+	{90, "XC"}, {50, "L"}, {40, "XL"}, {10, "X"}, {9, "IX"}, {5, "V"},
+	{4, "IV"}, {1, "I"},
+}
+
+// toRoman returns the upper case roman numeral of n.
+func toRoman(n int) (s string) {
+	for _, r := range romanNumerals {
+		for ; n >= r.value; n -= r.value {
+			s += r.numeral
+		}
+	}
+	return
+}
+
+// romanValue returns the value of the roman numeral s, which must be all
+// upper or all lower case, or 0 if s is not a roman numeral. As in docutils,
+// the values are limited to 4999, and numerals must be in their canonical
+// form, so "IIII" and "IC" are not numerals.
+func romanValue(s string) int {
+	upper := strings.ToUpper(s)
+	if s != upper && s != strings.ToLower(s) {
+		return 0
+	}
+	n, rest := 0, upper
+	for _, r := range romanNumerals {
+		for strings.HasPrefix(rest, r.numeral) {
+			n += r.value
+			rest = rest[len(r.numeral):]
+		}
+	}
+	if rest != "" || n > 4999 || toRoman(n) != upper {
+		return 0
+	}
+	return n
 }
 
 // func isInlineMarkup(r rune) bool {
@@ -375,6 +710,10 @@ func isSection(l *lexer) (found bool) {
 	checkLine := func(input string, skipSpace bool) (a bool) {
 		end := 2
 		for j := 0; j < end; j++ {
+			if l.start+j >= len(input) {
+				// The line is shorter than the position
+				return false
+			}
 			r, _ := utf8.DecodeRuneInString(input[l.start+j:])
 			if skipSpace && isSpace(r) {
 				log.Debugln("Skipping space rune")
@@ -386,6 +725,9 @@ func isSection(l *lexer) (found bool) {
 				return
 			}
 		}
+		// The rest of the line must repeat the same adornment rune,
+		// otherwise it is text, such as an attribution ("-- Author").
+		a = isAdornmentLine(input)
 		return
 	}
 
@@ -396,13 +738,26 @@ func isSection(l *lexer) (found bool) {
 		goto exit
 	}
 
+	nLine = l.peekNextLine()
 	if checkLine(l.currentLine(), false) {
+		// A short adornment line surrounded by blank lines is
+		// paragraph text, such as "::" or "==". The underline of a
+		// title ends the title, as a blank line does.
+		pBlankLine := l.line == 0 || l.lastLineIsBlankLine() ||
+			l.lastItem != nil &&
+				l.lastItem.Type == itemSectionAdornment &&
+				l.isBelowUnderline()
+		short := utf8.RuneCountInString(strings.TrimSpace(
+			l.currentLine())) < minTransitionLength
+		if pBlankLine && short && strings.TrimSpace(nLine) == "" {
+			log.Debugln("Found short adornment line without title")
+			goto exit
+		}
 		log.Debugln("Found section adornment")
 		found = true
 		goto exit
 	}
 
-	nLine = l.peekNextLine()
 	if nLine != "" {
 		if checkLine(nLine, true) {
 			log.Debugln("Found section adornment")
@@ -430,14 +785,52 @@ func isSectionAdornment(r rune) bool {
 	return false
 }
 
+// isAdornmentLine returns true if input, ignoring surrounding whitespace,
+// consists of a single repeated section adornment rune.
+func isAdornmentLine(input string) bool {
+	input = strings.TrimSpace(input)
+	first, _ := utf8.DecodeRuneInString(input)
+	if !isSectionAdornment(first) {
+		return false
+	}
+	for _, r := range input {
+		if r != first {
+			return false
+		}
+	}
+	return true
+}
+
+// minTransitionLength is the minimum number of adornment characters in a
+// transition. As in docutils, shorter adornment lines are paragraph text.
+const minTransitionLength = 4
+
+// isBelowUnderline returns true if the previous line is the underline of a
+// section title, that is an adornment line directly below text.
+func (l *lexer) isBelowUnderline() bool {
+	if l.line < 2 {
+		return false
+	}
+	title := l.lines[l.line-2]
+	return strings.TrimSpace(title) != "" && !isAdornmentLine(title)
+}
+
+// isTransition returns true if the current line is a transition marker. As in
+// docutils, the body of a section begins after the underline of its title, so
+// an adornment line directly below an underline is a transition, as it is
+// after a blank line.
 func isTransition(l *lexer) bool {
 	log.Debugln("START")
-	if r := l.peek(); !isSectionAdornment(l.mark) || !isSectionAdornment(r) {
+	if r := l.peek(); !isSectionAdornment(l.mark) || !isSectionAdornment(r) ||
+		!isAdornmentLine(l.currentLine()) ||
+		utf8.RuneCountInString(strings.TrimSpace(l.currentLine())) <
+			minTransitionLength {
 		log.Debugln("Transition not found")
 		return false
 	}
-	pBlankLine := l.lastItem != nil && l.lastItem.Type == itemBlankLine
-	nBlankLine := l.peekNextLine() == ""
+	pBlankLine := l.lastItem != nil && (l.lastItem.Type == itemBlankLine ||
+		l.lastItem.Type == itemSectionAdornment && l.isBelowUnderline())
+	nBlankLine := strings.TrimSpace(l.peekNextLine()) == ""
 	if l.line == 0 && nBlankLine {
 		log.Debugln("Found transition (followed by newline)")
 		return true
@@ -472,27 +865,206 @@ func isComment(l *lexer) bool {
 	return false
 }
 
-func isEnumList(l *lexer) (ret bool) {
+// isEnumList returns true if the current position begins an enumerated list
+// item. As in docutils, the enumerator must be followed by a space or the end
+// of the line, and the item must be followed by the end of the input, a blank
+// or indented line, or a line beginning with the next enumerator. Otherwise
+// text such as "A. Einstein was here" begins a paragraph.
+func isEnumList(l *lexer) bool {
 	log.Debugln("START")
-	bCount := 0
+	defer log.Debugln("END")
 	if isSection(l) {
-		goto exit
+		return false
 	}
-	if isArabic(l.mark) {
-		for {
-			bCount++
-			if nMark, _ := l.next(); !isArabic(nMark) {
-				if nMark == '.' || nMark == ' ' {
-					log.Debugln("Found arabic enum list!")
-					ret = true
-					goto exit
-				}
+	e, ok := scanEnumerator(l.currentLine()[l.index:])
+	if !ok {
+		return false
+	}
+	if l.isLastLine() {
+		return true
+	}
+	next := l.lines[l.line+1]
+	text := strings.TrimLeft(next, " ")
+	if indent := len(next) - len(text); text == "" || indent != l.index {
+		// Lines indented less than the item end the enclosing indented
+		// block, so they are treated like the end of the input.
+		return true
+	}
+	return strings.HasPrefix(text, e.next()) ||
+		strings.HasPrefix(text, e.prefix+"#"+e.suffix)
+}
+
+// isFieldList returns true if the current position begins a field of a field
+// list, which is a field marker such as ":Author:" followed by a space or the
+// end of the line.
+func isFieldList(l *lexer) bool {
+	return l.mark == ':' && fieldMarkerEnd(l.currentLine()[l.index:]) != -1
+}
+
+// fieldMarkerEnd returns the index of the colon ending the field marker at the
+// start of s, or -1 if s does not begin with a field marker. As in docutils,
+// the field name must not begin with a colon or a space, or end with a space.
+// A colon followed by a space ends the name unless it is escaped with a
+// backslash, so the name of ":a\: b: text" is "a\: b".
+func fieldMarkerEnd(s string) int {
+	if len(s) < 3 || s[0] != ':' || s[1] == ':' || isSpace(rune(s[1])) {
+		return -1
+	}
+	for i := 1; i < len(s); i++ {
+		switch {
+		case s[i] == '\\':
+			i++
+		case s[i] != ':':
+		case i+1 < len(s) && s[i+1] == '`':
+			// The colon begins an interpreted text role
+			return -1
+		case i+1 == len(s) || isSpace(rune(s[i+1])):
+			if isSpace(rune(s[i-1])) {
+				return -1
 			}
+			return i
 		}
 	}
-exit:
-	l.backup(bCount)
-	log.Debugln("END")
+	return -1
+}
+
+// isOptionList returns true if the current position begins an item of an
+// option list, which is an option group such as "-a, --all" followed by the
+// description of the options. As in docutils, the description follows the
+// group after at least two spaces, or begins on the next non-blank line,
+// which is indented more than the group. Otherwise text such as "-1 is
+// negative" or "--help" alone on a line is a paragraph.
+func isOptionList(l *lexer) bool {
+	if !strings.ContainsRune("-+/", l.mark) || isSection(l) {
+		return false
+	}
+	line := l.currentLine()
+	opts, ok := scanOptionGroup(line, l.index)
+	if !ok {
+		return false
+	}
+	if strings.TrimSpace(line[opts[len(opts)-1].end:]) != "" {
+		return true
+	}
+	next := l.line + 1
+	for next < len(l.lines) &&
+		strings.TrimFunc(l.lines[next], l.isIndentSpace) == "" {
+		next++
+	}
+	return next < len(l.lines) &&
+		l.indentOf(l.lines[next]) > l.indentOf(line[:l.index])
+}
+
+// optionSpan is an option of an option group found by scanOptionGroup. The
+// option string is s[start:nameEnd] and the argument is s[argStart:end],
+// which is empty if the option has no argument. The delimiter of the
+// argument, a space, "=" or nothing, is s[nameEnd:argStart].
+type optionSpan struct {
+	start, nameEnd, argStart, end int
+}
+
+// scanOptionGroup scans the option group beginning at index i of s. The
+// options of the group are separated by ", ", and the group ends at the end
+// of s or before two spaces. The options have the syntax used by docutils:
+//
+//	-a, +a      Short options, the argument follows a space or nothing
+//	--all       Long options, the argument follows a space or "="
+//	/V          DOS/VMS options, like long options
+//
+// An argument is a letter followed by letters, digits, "_" and "-", or text
+// without angle brackets enclosed in them, such as "<output file>". As with
+// the regular expression of docutils, an option is scanned with its argument
+// first, and then without it if the rest of s does not continue the group.
+func scanOptionGroup(s string, i int) (opts []optionSpan, ok bool) {
+	nameEnd := scanOptionName(s, i)
+	if nameEnd == -1 {
+		return nil, false
+	}
+	tries := []optionSpan{{i, nameEnd, nameEnd, nameEnd}}
+	if argStart, end := scanOptionArgument(s, i, nameEnd); end != -1 {
+		arg := optionSpan{i, nameEnd, argStart, end}
+		tries = append([]optionSpan{arg}, tries...)
+	}
+	for _, o := range tries {
+		rest := s[o.end:]
+		if strings.HasPrefix(rest, ", ") {
+			if more, ok := scanOptionGroup(s, o.end+2); ok {
+				return append([]optionSpan{o}, more...), true
+			}
+		} else if strings.TrimRight(rest, " ") == "" ||
+			strings.HasPrefix(rest, "  ") {
+			return []optionSpan{o}, true
+		}
+	}
+	return nil, false
+}
+
+// isLongOption returns true if the option at the start of s is a long or a
+// DOS/VMS option.
+func isLongOption(s string) bool {
+	return strings.HasPrefix(s, "--") || strings.HasPrefix(s, "/")
+}
+
+// isOptionRune returns true if s[i] is an ASCII letter or digit, or one of
+// the other runes in extra.
+func isOptionRune(s string, i int, extra string) bool {
+	if i >= len(s) {
+		return false
+	}
+	c := s[i]
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' ||
+		'0' <= c && c <= '9' || strings.IndexByte(extra, c) != -1
+}
+
+// scanOptionName returns the end of the option string beginning at index i of
+// s, or -1 if there is none.
+func scanOptionName(s string, i int) int {
+	switch {
+	case isLongOption(s[i:]):
+		if s[i] == '-' {
+			i++
+		}
+		if i++; !isOptionRune(s, i, "") {
+			return -1
+		}
+		for i++; isOptionRune(s, i, "_-"); i++ {
+		}
+		return i
+	case s[i] == '-' || s[i] == '+':
+		if !isOptionRune(s, i+1, "") {
+			return -1
+		}
+		return i + 2
+	}
+	return -1
+}
+
+// scanOptionArgument returns the start and end of the argument of the option
+// string s[i:nameEnd], or -1 if it is not followed by an argument.
+func scanOptionArgument(s string, i, nameEnd int) (argStart, end int) {
+	argStart = nameEnd
+	switch {
+	case argStart == len(s):
+		return -1, -1
+	case s[argStart] == ' ', s[argStart] == '=' && isLongOption(s[i:]):
+		argStart++
+	case isLongOption(s[i:]):
+		// Only short options take an argument without a delimiter.
+		return -1, -1
+	}
+	if strings.HasPrefix(s[argStart:], "<") {
+		n := strings.IndexAny(s[argStart+1:], "<>")
+		if n < 1 || s[argStart+1+n] != '>' {
+			return -1, -1
+		}
+		return argStart, argStart + n + 2
+	}
+	if end = argStart; !isOptionRune(s, end, "") ||
+		!unicode.IsLetter(rune(s[end])) {
+		return -1, -1
+	}
+	for end++; isOptionRune(s, end, "_-"); end++ {
+	}
 	return
 }
 
@@ -511,7 +1083,8 @@ func isBulletList(l *lexer) bool {
 		log.Debugln("NO BULLET FOR YOU!")
 		goto exit
 	}
-	if l.peek() == ' ' {
+	// A bullet alone on a line begins an empty list item.
+	if r := l.peek(); r == ' ' || r == utf8.RuneError {
 		log.Debugln("I haz bullet!")
 		ret = true
 	}
@@ -520,30 +1093,37 @@ exit:
 	return ret
 }
 
+// isDefinitionTerm returns true if the current line is the term of a
+// definition list item. As in docutils, the term begins a block of text and
+// the next line is indented more than the term. A block begins at the start of
+// the input, after a blank line, and after a line with other indentation, such
+// as the term of an enclosing definition list.
 func isDefinitionTerm(l *lexer) bool {
 	log.Debugln("START")
-	// Definition terms are preceded by a blankline
-	if l.line != 0 && !l.lastLineIsBlankLine() {
-		log.Debugln("Not definition, lastLineIsBlankLine == false")
+	defer log.Debugln("END")
+	indent := l.indentOf(l.currentLine()[:l.index])
+	if l.line != 0 && !l.lastLineIsBlankLine() &&
+		l.indentOf(l.lines[l.line-1]) == indent {
+		log.Debugln("Not definition, the line continues a block")
 		return false
 	}
 	nL := l.peekNextLine()
-	sCount := 0
-	for {
-		if sCount < len(nL) && isSpace(rune(nL[sCount])) {
-			sCount++
-		} else {
+	if strings.TrimFunc(nL, l.isIndentSpace) == "" {
+		log.Debugln("Not definition, next line is blank")
+		return false
+	}
+	return l.indentOf(nL) > indent
+}
+
+// indentOf returns the number of runes indenting line.
+func (l *lexer) indentOf(line string) (n int) {
+	for _, r := range line {
+		if !l.isIndentSpace(r) {
 			break
 		}
+		n++
 	}
-	log.Debugln("sCount =", sCount)
-	if sCount >= 2 {
-		log.Debugln("Found definition term!")
-		return true
-	}
-	log.Debugln("Did not find definition term.")
-	log.Debugln("END")
-	return false
+	return
 }
 
 func isBlockquote(l *lexer) bool {
@@ -556,9 +1136,210 @@ func isBlockquote(l *lexer) bool {
 	return false
 }
 
-// lexStart is the first stateFn called by run(). From here other stateFn's are
+// attributionMarker returns the length in bytes of the attribution marker,
+// and the spaces following it, at the beginning of text. An attribution marker
+// is two or three hyphens, or an em dash, followed by the attribution text. If
+// text does not begin with an attribution marker, -1 is returned.
+func attributionMarker(text string) int {
+	var rest string
+	switch {
+	case strings.HasPrefix(text, "---"):
+		rest = text[3:]
+	case strings.HasPrefix(text, "--"):
+		rest = text[2:]
+	case strings.HasPrefix(text, "\u2014"):
+		rest = text[len("\u2014"):]
+	default:
+		return -1
+	}
+	if strings.HasPrefix(rest, "-") {
+		return -1
+	}
+	rest = strings.TrimLeft(rest, " \t")
+	if rest == "" {
+		return -1
+	}
+	return len(text) - len(rest)
+}
+
+// isAttribution returns true if the indented text at the current position is
+// the attribution of a block quote. As in docutils, an attribution begins with
+// an attribution marker after a blank line, follows other text of the block
+// quote, and the lines continuing it are indented consistently. Text following
+// an attribution at the same indent begins a new block quote, so it cannot be
+// an attribution itself.
+func isAttribution(l *lexer) bool {
+	if !l.lastLineIsBlankLine() || l.lastItem == nil ||
+		l.lastItem.Type != itemSpace {
+		return false
+	}
+	line := l.currentLine()
+	if attributionMarker(line[l.index:]) == -1 {
+		return false
+	}
+	indent := l.indentOf(line[:l.index])
+	prev := l.line - 1
+	for prev >= 0 &&
+		strings.TrimFunc(l.lines[prev], l.isIndentSpace) == "" {
+		prev--
+	}
+	if prev < 0 || l.indentOf(l.lines[prev]) < indent ||
+		prev == l.attributionEnd && l.attributionIndent == indent {
+		return false
+	}
+	end, cIndent := l.line, -1
+	for _, next := range l.lines[l.line+1:] {
+		w := l.indentOf(next)
+		if strings.TrimFunc(next, l.isIndentSpace) == "" || w == 0 {
+			break
+		}
+		if cIndent != -1 && w != cIndent {
+			log.Debugln("Not attribution, inconsistent indentation")
+			return false
+		}
+		cIndent = w
+		end++
+	}
+	l.attributionStart, l.attributionEnd = l.line, end
+	l.attributionIndent = indent
+	return true
+}
+
+// continuesAttribution returns true if the indented text at the current
+// position is a line continuing the attribution found by isAttribution.
+func (l *lexer) continuesAttribution() bool {
+	return l.lastItem != nil && l.lastItem.Type == itemSpace &&
+		l.line > l.attributionStart && l.line <= l.attributionEnd
+}
+
+// lineClass is the construct begun at the current lexer position, as decided
+// by classifyLine.
+type lineClass int
+
+const (
+	lineParagraph lineClass = iota
+	lineComment
+	lineBullet
+	lineEnumList
+	lineSection
+	lineTransition
+	lineSpace
+	lineBlockquote
+	lineDefinitionTerm
+	lineFieldList
+	lineOptionList
+	lineDoctestBlock
+	lineDirective
+	lineAttribution
+)
+
+// classifyLine decides the construct begun at the current lexer position. The
+// order of the checks is the precedence used by docutils, so ambiguous lines
+// such as "----" and "*****" are classified the same way:
+//
+//  1. A line continuing a paragraph is text, unless it is the underline of a
+//     section title. "* item" directly below a paragraph line does not begin a
+//     bullet list. Text indented directly below a paragraph of more than one
+//     line begins a block quote. The line directly below an overline is
+//     title text, whatever construct it reads as.
+//  2. Bullets, then enumerators, then field markers, then option groups,
+//     then doctest blocks, then explicit markup. Explicit markup is a
+//     directive if the marker is followed by a name and "::", otherwise it
+//     is a comment.
+//     "* * *" is a bullet list item, even though it could be read as a
+//     transition.
+//  3. Adornment lines. Directly below text, an adornment line is an
+//     underline. With blank lines, or the start of the input, above and below
+//     it, it is a transition. After a blank line and directly above text, it
+//     is an overline, even if no underline follows; the parser reports the
+//     incomplete title.
+//  4. Indentation, attributions, definition terms, block quotes, and finally
+//     paragraphs. An indented line that is both an attribution and a
+//     definition term, with the same indentation on the next line, is an
+//     attribution.
+func classifyLine(l *lexer) lineClass {
+	switch {
+	case l.continuesAttribution():
+		return lineAttribution
+	case l.continuesParagraph():
+		if isSection(l) {
+			return lineSection
+		}
+		return lineParagraph
+	case l.followsOverline():
+		if isSection(l) {
+			return lineSection
+		} else if isSpace(l.mark) || (l.index == 0 && l.isIndentSpace(l.mark)) {
+			return lineSpace
+		}
+		return lineParagraph
+	case l.isUnexpectedIndent():
+		return lineBlockquote
+	case isBulletList(l):
+		return lineBullet
+	case isEnumList(l):
+		return lineEnumList
+	case isFieldList(l):
+		return lineFieldList
+	case isOptionList(l):
+		return lineOptionList
+	case isDoctestBlock(l):
+		return lineDoctestBlock
+	case isDirective(l):
+		return lineDirective
+	case isComment(l):
+		return lineComment
+	case isSection(l):
+		return lineSection
+	case isTransition(l):
+		return lineTransition
+	case isSpace(l.mark) || (l.index == 0 && l.isIndentSpace(l.mark)):
+		return lineSpace
+	case isAttribution(l):
+		return lineAttribution
+	case isDefinitionTerm(l):
+		return lineDefinitionTerm
+	case isBlockquote(l):
+		return lineBlockquote
+	}
+	return lineParagraph
+}
+
+// continuesParagraph returns true if the text at the current position
+// continues the paragraph of the previous line, which is the case when the
+// previous line is paragraph text beginning at the same column.
+func (l *lexer) continuesParagraph() bool {
+	p := l.lastParagraph
+	return p != nil && int(p.Line) == l.lineNumber()-1 &&
+		int(p.StartPosition) == l.index+1 && !isSpace(l.mark) &&
+		!l.isIndentSpace(l.mark)
+}
+
+// followsOverline returns true if the line above the current position is an
+// overline, a section adornment that is not the underline of a title. As in
+// docutils, the line below an overline is the title, even if it reads as
+// another construct, such as a bullet list item or a field. Without an
+// underline, the line is paragraph text, and the parser reports the incomplete
+// title.
+func (l *lexer) followsOverline() bool {
+	a := l.lastAdornment
+	return a != nil && int(a.Line) == l.lineNumber()-1 && !l.isBelowUnderline()
+}
+
+// isUnexpectedIndent returns true if the text at the current position is
+// indented from the paragraph of the previous line, without a blank line
+// between them. As in docutils, the indented text begins a block quote unless
+// the paragraph is a single line, which is then a definition term.
+func (l *lexer) isUnexpectedIndent() bool {
+	p := l.lastParagraph
+	return p != nil && l.paragraphLines > 1 &&
+		int(p.Line) == l.lineNumber()-1 && l.lastItem.Type == itemSpace &&
+		l.index+1 > int(p.StartPosition)
+}
+
+// lexStart is the first stateFn run by step. From here other stateFn's are
 // called depending on the input. When this function returns nil, the lexing is
-// finished and run() will exit.
+// finished and nextItem returns nil once every emitted item has been read.
 func lexStart(l *lexer) stateFn {
 	log.Debugln("START")
 	for {
@@ -571,37 +1352,53 @@ func lexStart(l *lexer) stateFn {
 				l.indentLevel = 0
 				l.indentWidth = ""
 			}
+			if l.index == 0 && l.isWhitespaceLine() {
+				lexWhitespaceLine(l)
+				continue
+			}
 			log.Debugf("l.index: %d, l.width: %d, l.line: %d\n",
 				l.index, l.width, l.lineNumber())
-			if isComment(l) {
+			switch classifyLine(l) {
+			case lineComment:
 				return lexComment
-			} else if isBulletList(l) {
+			case lineBullet:
 				return lexBullet
-			} else if isEnumList(l) {
+			case lineEnumList:
 				return lexEnumList
-			} else if isSection(l) {
+			case lineSection:
 				return lexSection
-			} else if isTransition(l) {
+			case lineTransition:
 				return lexTransition
-			} else if isSpace(l.mark) {
+			case lineSpace:
 				return lexSpace
-			} else if isBlockquote(l) {
+			case lineBlockquote:
 				return lexBlockquote
-			} else if isDefinitionTerm(l) {
+			case lineDefinitionTerm:
 				return lexDefinitionTerm
-			} else {
+			case lineAttribution:
+				return lexAttribution
+			case lineFieldList:
+				return lexFieldList
+			case lineOptionList:
+				return lexOptionList
+			case lineDoctestBlock:
+				return lexDoctestBlock
+			case lineDirective:
+				return lexDirective
+			default:
 				return lexParagraph
 			}
 
 		} else if l.isEndOfLine() {
 			log.Debugln("isEndOfLine == true")
 			if l.start == l.index {
-				if l.start == 0 && len(l.currentLine()) == 0 {
+				// The empty "line" following the final newline of
+				// the input is not a blank line, it is the end of
+				// the input.
+				if l.start == 0 && len(l.currentLine()) == 0 &&
+					!l.isLastLine() {
 					log.Debugln("Found blank line")
 					l.emit(itemBlankLine)
-					if l.isLastLine() {
-						break
-					}
 				} else if l.isLastLine() {
 					log.Debugln("Found end of last line")
 					break
@@ -612,19 +1409,35 @@ func lexStart(l *lexer) stateFn {
 	}
 
 	l.emit(itemEOF)
-	close(l.items)
 	log.Debugln("END")
 	return nil
 }
 
+// lexWhitespaceLine consumes a line containing only whitespace. As in docutils,
+// the line is a blank line, unless it is the last line of the input, which is
+// the end of the input.
+func lexWhitespaceLine(l *lexer) {
+	l.gotoLocation(len(l.currentLine()), l.lineNumber())
+	if !l.isLastLine() {
+		log.Debugln("Found whitespace only line")
+		l.emit(itemBlankLine)
+	}
+	l.start = l.index
+}
+
 // lexSpace consumes space characters (space and tab) in the input and emits a
-// itemSpace token.
+// itemSpace token. At the start of a line, the space characters are those
+// accepted by isIndentSpace.
 func lexSpace(l *lexer) stateFn {
 	log.Debugln("START")
 	log.Debugln("l.mark ==", l.mark)
-	for isSpace(l.mark) {
-		log.Debugln("isSpace ==", isSpace(l.mark))
-		if r := l.peek(); isSpace(r) {
+	space := isSpace
+	if l.start == 0 {
+		space = l.isIndentSpace
+	}
+	for space(l.mark) {
+		log.Debugln("isSpace ==", space(l.mark))
+		if r := l.peek(); space(r) {
 			l.next()
 		} else {
 			log.Debugln("Next mark is not space!")
@@ -642,21 +1455,25 @@ func lexSpace(l *lexer) stateFn {
 
 // lexSection is used after isSection() has determined that the next runes of
 // input are section.  From here, the lexTitle() and lexSectionAdornment() are
-// called based on the input.
+// called based on the input. A line is only an adornment if it repeats a
+// single adornment rune, a line such as "=Title=" or "=-=-=" is lexed as a
+// title.
 func lexSection(l *lexer) stateFn {
 	log.Debugln("START")
 	// log.Debugf("l.mark: %#U, l.index: %d, l.start: %d, l.width: %d, " +
 	// "l.line: %d\n", l.mark, l.index, l.start, l.width, l.lineNumber())
-	if isSectionAdornment(l.mark) {
+	if isSectionAdornment(l.mark) && isAdornmentLine(l.currentLine()) {
 		if l.lastItem != nil && l.lastItem.Type != itemTitle {
 			return lexSectionAdornment
 		}
 		lexSectionAdornment(l)
-	} else if isSpace(l.mark) {
+	} else if isSpace(l.mark) || (l.index == 0 && l.isIndentSpace(l.mark)) {
 		return lexSpace
-	} else if l.mark == utf8.RuneError {
+	} else if l.mark == utf8.RuneError && l.isEndOfLine() {
 		l.next()
-	} else if unicode.IsPrint(l.mark) {
+	} else {
+		// Any other rune begins the title, including the spaces that
+		// are not indentation, such as U+3000, and invisible controls.
 		return lexTitle
 	}
 	log.Debugln("END")
@@ -681,13 +1498,19 @@ func lexTitle(l *lexer) stateFn {
 }
 
 // lexSectionAdornment advances the lexer until a newline is encountered and
-// emits a itemSectionAdornment token. Control is returned to lexSection() on
-// completion.
+// emits a itemSectionAdornment token. Trailing spaces are not part of the
+// adornment and are ignored, as they are by docutils. Control is returned to
+// lexSection() on completion.
 func lexSectionAdornment(l *lexer) stateFn {
 	log.Debugln("START")
 	for {
-		if l.isEndOfLine() {
+		if l.isEndOfLine() || (isSpace(l.mark) &&
+			strings.TrimSpace(l.currentLine()[l.index:]) == "") {
 			l.emit(itemSectionAdornment)
+			for !l.isEndOfLine() {
+				l.next()
+			}
+			l.start = l.index
 			if l.mark == utf8.RuneError {
 				break
 			}
@@ -712,28 +1535,62 @@ func lexTransition(l *lexer) stateFn {
 	return lexStart
 }
 
+// lexEnumList emits the enumerator of an enumerated list item found by
+// isEnumList, followed by the item body. The affixes of the enumerator are
+// emitted as itemEnumListAffix and the enumerator text as the item of its
+// sequence, so "(iv) text" is lexed as the items "(", "iv", ")", " ", and
+// "text".
 func lexEnumList(l *lexer) stateFn {
 	log.Debugln("START")
-	if isArabic(l.mark) {
-		for {
-			if nMark, _ := l.next(); !isArabic(nMark) {
-				l.emit(itemEnumListArabic)
-				l.next()
-				if nMark == '.' {
-					l.emit(itemEnumListAffix)
-					l.next()
-				}
-				l.emit(itemSpace)
-				break
-			}
-		}
+	start := l.index
+	e, _ := scanEnumerator(l.currentLine()[l.index:])
+	if e.prefix != "" {
+		l.next()
+		l.emit(itemEnumListAffix)
+	}
+	for range e.text {
+		l.next()
+	}
+	l.emit(e.seq)
+	l.next()
+	l.emit(itemEnumListAffix)
+	if l.isEndOfLine() {
+		// The item is empty
+		l.indentLevel++
+		return lexStart
+	}
+	lexSpace(l)
+	// Lines indented to the body of the item continue it.
+	l.indentWidth += strings.Repeat(" ", l.index-start)
+	l.indentLevel++
+	if !l.isEndOfLine() {
+		lexParagraph(l)
 	}
 	log.Debugln("END")
 	return lexStart
 }
 
+// lexParagraph emits a line of a paragraph. If the line ends with the
+// literal block marker "::", the literal block that follows it is lexed by
+// lexLiteralBlock.
 func lexParagraph(l *lexer) stateFn {
 	log.Debugln("START")
+	column := utf8.RuneCountInString(l.currentLine()[:l.start])
+	marker := l.line + 1
+	if !hasLiteralMarker(l.currentLine()) {
+		marker = -1
+	}
+	lexText(l)
+	if l.line == marker {
+		lexLiteralBlock(l, column)
+	}
+	log.Debugln("END")
+	return lexStart
+}
+
+// lexText emits the rest of the line as an itemParagraph and moves to the next
+// line.
+func lexText(l *lexer) {
 	for {
 		l.next()
 		if l.isEndOfLine() && l.mark == utf8.RuneError {
@@ -742,12 +1599,130 @@ func lexParagraph(l *lexer) stateFn {
 		}
 	}
 	l.nextLine()
-	log.Debugln("END")
-	return lexStart
 }
 
+// hasLiteralMarker returns true if text ends with the literal block marker
+// "::", which is not escaped by a backslash.
+func hasLiteralMarker(text string) bool {
+	if !strings.HasSuffix(text, "::") {
+		return false
+	}
+	escaped := false
+	for i := len(text) - 3; i >= 0 && text[i] == '\\'; i-- {
+		escaped = !escaped
+	}
+	return !escaped
+}
+
+// lexLiteralBlock emits the literal block following a paragraph line that
+// ends with the literal block marker, if there is one. As in docutils, the
+// block begins after a blank line and contains the lines indented more than
+// column, the column of the paragraph, and the blank lines between them. The
+// lines are emitted by lexIndentedLines as itemLiteralBlock, so the text of the
+// block is kept as written.
+func lexLiteralBlock(l *lexer, column int) {
+	if l.line == len(l.lines)-1 || !l.isBlankLine(l.line) {
+		return
+	}
+	if !lexIndentedLines(l, column, itemLiteralBlock) {
+		lexQuotedLiteralBlock(l, column)
+	}
+}
+
+// lexIndentedLines emits the lines beginning at the current line that are
+// indented more than column, and the blank lines between them. The
+// indentation common to the lines is emitted as an itemSpace and the rest of
+// each line, including any further indentation, as elem. The blank lines are
+// emitted as itemBlankLine. If no line is indented more than column before
+// the first line that is not, nothing is emitted and false is returned.
+func lexIndentedLines(l *lexer, column int, elem itemElement) bool {
+	last, indent := -1, -1
+	for n := l.line; n < len(l.lines); n++ {
+		if l.isBlankLine(n) {
+			continue
+		}
+		w := l.indentOf(l.lines[n])
+		if w <= column {
+			break
+		}
+		if last = n; indent == -1 || w < indent {
+			indent = w
+		}
+	}
+	for n := l.line; n <= last; n++ {
+		line := l.currentLine()
+		if l.isBlankLine(n) {
+			lexWhitespaceLine(l)
+			l.nextLine()
+			continue
+		}
+		start := 0
+		for i := 0; i < indent; i++ {
+			_, w := utf8.DecodeRuneInString(line[start:])
+			start += w
+		}
+		l.gotoLocation(start, l.lineNumber())
+		l.emit(itemSpace)
+		l.gotoLocation(len(line), l.lineNumber())
+		l.emit(elem)
+		l.nextLine()
+	}
+	return last != -1
+}
+
+// lexQuotedLiteralBlock emits the quoted literal block following the blank
+// lines at the current line, if there is one. A quoted literal block is not
+// indented from column, and each of its lines begins with the same
+// sectionAdornments rune, which is kept in the text of the block. The block
+// ends at the first line that is blank or does not begin with the quote. Each
+// line is emitted as an itemLiteralBlock, without an itemSpace for the
+// indentation, so the parser can tell the block from an indented one.
+func lexQuotedLiteralBlock(l *lexer, column int) {
+	first := l.line
+	for first < len(l.lines) && l.isBlankLine(first) {
+		first++
+	}
+	quote := func(n int) rune {
+		line := l.lines[n]
+		if l.indentOf(line) != column {
+			return utf8.RuneError
+		}
+		for i := 0; i < column; i++ {
+			_, w := utf8.DecodeRuneInString(line)
+			line = line[w:]
+		}
+		r, _ := utf8.DecodeRuneInString(line)
+		return r
+	}
+	if first == len(l.lines) || !isSectionAdornment(quote(first)) {
+		return
+	}
+	q := quote(first)
+	last := first
+	for last+1 < len(l.lines) && quote(last+1) == q {
+		last++
+	}
+	for n := l.line; n <= last; n++ {
+		line := l.currentLine()
+		if n < first {
+			lexWhitespaceLine(l)
+			l.nextLine()
+			continue
+		}
+		start := len(line) - len(strings.TrimLeftFunc(line, l.isIndentSpace))
+		l.gotoLocation(start, l.lineNumber())
+		l.start = l.index
+		l.gotoLocation(len(line), l.lineNumber())
+		l.emit(itemLiteralBlock)
+		l.nextLine()
+	}
+}
+
+// lexComment emits the comment marker, the text following it on its line,
+// and the body of the comment found by lexCommentBody.
 func lexComment(l *lexer) stateFn {
 	log.Debugln("START")
+	column := l.indentOf(l.currentLine())
 	for l.mark == '.' {
 		l.next()
 	}
@@ -755,12 +1730,31 @@ func lexComment(l *lexer) stateFn {
 	if l.mark != utf8.RuneError {
 		l.next()
 		lexSpace(l)
-		lexParagraph(l)
+		// The text of a comment does not introduce a literal block.
+		lexText(l)
+		lexCommentBody(l, column, true)
+	} else {
+		l.nextLine()
+		lexCommentBody(l, column, false)
 	}
 	log.Debugln("END")
 	return lexStart
 }
 
+// lexCommentBody emits the body of a comment whose marker is at column. As in
+// docutils, the body contains the lines indented more than column and the
+// blank lines between them, so that no line of the body begins another
+// element. If the marker is alone on its line, afterText is false and the
+// body must begin on the next line; a marker followed by a blank line is an
+// empty comment. The lines are emitted by lexIndentedLines as itemParagraph,
+// so the text of the comment keeps any further indentation.
+func lexCommentBody(l *lexer, column int, afterText bool) {
+	if !afterText && l.isBlankLine(l.line) {
+		return
+	}
+	lexIndentedLines(l, column, itemParagraph)
+}
+
 func lexBlockquote(l *lexer) stateFn {
 	log.Debugln("START")
 	for {
@@ -775,34 +1769,170 @@ func lexBlockquote(l *lexer) stateFn {
 	return lexStart
 }
 
+// lexAttribution emits a line of an attribution found by isAttribution. The
+// first line begins with the attribution marker.
+func lexAttribution(l *lexer) stateFn {
+	log.Debugln("START")
+	l.gotoLocation(len(l.currentLine()), l.lineNumber())
+	l.emit(itemAttribution)
+	l.nextLine()
+	log.Debugln("END")
+	return lexStart
+}
+
+// lexDefinitionTerm emits the term of a definition list item found by
+// isDefinitionTerm. The classifiers following the term, such as "cls" in
+// "term : cls", are emitted as itemClassifierDelimiter and itemClassifier. The
+// indented lines of the definition are lexed by lexStart, so a definition can
+// contain any body element, including another definition list.
 func lexDefinitionTerm(l *lexer) stateFn {
 	log.Debugln("START")
+	elem := itemDefinitionTerm
 	for {
-		l.next()
-		if l.isEndOfLine() && l.mark == utf8.RuneError {
-			l.emit(itemDefinitionTerm)
+		line := l.currentLine()
+		start, end := findClassifierDelimiter(line[l.index:])
+		if start == -1 {
+			l.gotoLocation(len(line), l.lineNumber())
+			l.emit(elem)
 			break
 		}
+		l.gotoLocation(l.index+start, l.lineNumber())
+		l.emit(elem)
+		l.gotoLocation(l.index+end-start, l.lineNumber())
+		l.emit(itemClassifierDelimiter)
+		elem = itemClassifier
 	}
 	l.nextLine()
+	log.Debugln("END")
+	return lexStart
+}
+
+// findClassifierDelimiter returns the start and end of the first classifier
+// delimiter in s, which is a colon with spaces on both sides, or -1 if there
+// is none. As in docutils, a colon at the end of s is not a delimiter, even
+// if it is followed by spaces.
+func findClassifierDelimiter(s string) (start, end int) {
+	for i := 1; i+1 < len(s); i++ {
+		if s[i] != ':' || s[i-1] != ' ' || s[i+1] != ' ' {
+			continue
+		}
+		start, end = i-1, i+2
+		for start > 0 && s[start-1] == ' ' {
+			start--
+		}
+		for end < len(s) && s[end] == ' ' {
+			end++
+		}
+		if end < len(s) {
+			return
+		}
+	}
+	return -1, -1
+}
+
+// lexFieldList emits a field of a field list found by isFieldList. The colons
+// of the field marker are emitted as itemFieldList and the name between them
+// as itemFieldName, so ":Author: J. Smith" is lexed as the items ":",
+// "Author", ":", " ", and "J. Smith". Each line of the field body is emitted
+// as an itemFieldBody. The body continues on the lines indented more than the
+// field marker, including those following blank lines.
+func lexFieldList(l *lexer) stateFn {
+	log.Debugln("START")
+	indent := l.indentOf(l.currentLine()[:l.index])
+	end := l.index + fieldMarkerEnd(l.currentLine()[l.index:])
 	l.next()
-	log.Debugf("Current line: %q\n", l.currentLine())
-	lexSpace(l)
-	for {
-		l.next()
-		if l.isEndOfLine() && l.mark == utf8.RuneError {
-			l.emit(itemParagraph)
-			break
+	l.emit(itemFieldList)
+	l.gotoLocation(end, l.lineNumber())
+	l.emit(itemFieldName)
+	l.next()
+	l.emit(itemFieldList)
+	if !l.isEndOfLine() {
+		lexSpace(l)
+	}
+	lexIndentedBody(l, itemFieldBody, indent)
+	log.Debugln("END")
+	return lexStart
+}
+
+// lexOptionList emits an item of an option list found by isOptionList. Each
+// option string is emitted as an itemOption and its argument as an
+// itemOptionArgument, following the "=" delimiter emitted as an
+// itemOptionList or a space. The commas separating the options are emitted as
+// itemOptionList, so "-f FILE, --file=FILE  Input." is lexed as the items
+// "-f", " ", "FILE", ",", " ", "--file", "=", "FILE", "  ", and "Input.". Each
+// line of the description is emitted as an itemOptionDescription.
+func lexOptionList(l *lexer) stateFn {
+	log.Debugln("START")
+	indent := l.indentOf(l.currentLine()[:l.index])
+	opts, _ := scanOptionGroup(l.currentLine(), l.index)
+	for n, o := range opts {
+		if n > 0 {
+			l.next()
+			l.emit(itemOptionList)
+			lexSpace(l)
+		}
+		l.gotoLocation(o.nameEnd, l.lineNumber())
+		l.emit(itemOption)
+		if o.argStart == o.end {
+			continue
+		}
+		if l.mark == '=' {
+			l.next()
+			l.emit(itemOptionList)
+		} else if l.mark == ' ' {
+			l.next()
+			l.emit(itemSpace)
 		}
+		l.gotoLocation(o.end, l.lineNumber())
+		l.emit(itemOptionArgument)
 	}
+	if !l.isEndOfLine() {
+		lexSpace(l)
+	}
+	lexIndentedBody(l, itemOptionDescription, indent)
 	log.Debugln("END")
 	return lexStart
 }
 
+// lexIndentedBody emits the rest of the current line, and the lines following
+// it that are indented more than indent, as items of type elem. The lines
+// after blank lines continue the body if they are indented. The indentation
+// of the continuing lines is emitted as an itemSpace.
+func lexIndentedBody(l *lexer, elem itemElement, indent int) {
+	for {
+		if line := l.currentLine(); l.index < len(line) {
+			l.gotoLocation(len(line), l.lineNumber())
+			l.emit(elem)
+		}
+		next := l.line + 1
+		for next < len(l.lines) &&
+			strings.TrimFunc(l.lines[next], l.isIndentSpace) == "" {
+			next++
+		}
+		if next == len(l.lines) || l.indentOf(l.lines[next]) <= indent {
+			break
+		}
+		for l.line+1 < next {
+			l.nextLine()
+			lexWhitespaceLine(l)
+		}
+		line := l.nextLine()
+		l.gotoLocation(len(line)-len(strings.TrimLeftFunc(line,
+			l.isIndentSpace)), l.lineNumber())
+		l.emit(itemSpace)
+	}
+	l.nextLine()
+}
+
 func lexBullet(l *lexer) stateFn {
 	log.Debugln("START")
 	l.next()
 	l.emit(itemBullet)
+	if l.isEndOfLine() {
+		// The item is empty
+		l.indentLevel++
+		return lexStart
+	}
 	lexSpace(l)
 	l.indentWidth += l.lastItem.Text + " "
 	lexParagraph(l)
@@ -810,3 +1940,220 @@ func lexBullet(l *lexer) stateFn {
 	log.Debugln("END")
 	return lexStart
 }
+
+// isDoctestBlock returns true if the text at the current position begins a
+// doctest block, which is the Python prompt ">>>" followed by a space or the
+// end of the line. A quoted literal block beginning with ">>>" is lexed by
+// lexQuotedLiteralBlock before the line is classified.
+func isDoctestBlock(l *lexer) bool {
+	text := l.currentLine()[l.index:]
+	return text == ">>>" || strings.HasPrefix(text, ">>> ")
+}
+
+// lexDoctestBlock emits the lines of a doctest block, which begins at the
+// current position and ends at the next blank line or at a line indented less
+// than the first. The text of the first line is emitted as an
+// itemDoctestBlock. On the following lines, the indentation of the first line
+// is emitted as an itemSpace, if there is any, and the rest of the line,
+// including any further indentation, as an itemDoctestBlock, so the output
+// of the session is kept as written.
+func lexDoctestBlock(l *lexer) stateFn {
+	log.Debugln("START")
+	column := utf8.RuneCountInString(l.currentLine()[:l.index])
+	l.gotoLocation(len(l.currentLine()), l.lineNumber())
+	l.emit(itemDoctestBlock)
+	for !l.isLastLine() {
+		next := l.lines[l.line+1]
+		if strings.TrimFunc(next, l.isIndentSpace) == "" ||
+			l.indentOf(next) < column {
+			break
+		}
+		l.nextLine()
+		start := 0
+		for i := 0; i < column; i++ {
+			_, w := utf8.DecodeRuneInString(next[start:])
+			start += w
+		}
+		if start > 0 {
+			l.gotoLocation(start, l.lineNumber())
+			l.emit(itemSpace)
+		}
+		l.gotoLocation(len(next), l.lineNumber())
+		l.emit(itemDoctestBlock)
+	}
+	l.nextLine()
+	log.Debugln("END")
+	return lexStart
+}
+
+// directiveMarker holds the offsets, from the start of the explicit markup,
+// of the parts of a directive marker such as ".. image:: picture.png".
+type directiveMarker struct {
+	nameStart, nameEnd int // The directive name
+	delimStart         int // The "::" following the name
+}
+
+// scanDirectiveMarker returns the directive marker at the start of s. As in
+// docutils, the marker is ".." and at least one space, followed by a simple
+// reference name, an optional space, and "::" followed by a space or the end
+// of the line. A simple reference name is made of letters and digits, which
+// can be joined by single hyphens, underscores, periods, colons, or plus
+// signs.
+func scanDirectiveMarker(s string) (m directiveMarker, ok bool) {
+	if !strings.HasPrefix(s, "..") || len(s) < 3 || !isSpace(rune(s[2])) {
+		return
+	}
+	isName := func(r rune) bool {
+		return unicode.IsLetter(r) || unicode.IsDigit(r)
+	}
+	i := 2
+	for i < len(s) && isSpace(rune(s[i])) {
+		i++
+	}
+	m.nameStart = i
+	for i < len(s) {
+		r, w := utf8.DecodeRuneInString(s[i:])
+		if isName(r) {
+			i += w
+			continue
+		}
+		n, _ := utf8.DecodeRuneInString(s[i+w:])
+		if i == m.nameStart || !strings.ContainsRune("-_.:+", r) ||
+			!isName(n) {
+			break
+		}
+		i += w
+	}
+	if m.nameEnd = i; i == m.nameStart {
+		return
+	}
+	if i < len(s) && s[i] == ' ' {
+		i++
+	}
+	m.delimStart = i
+	rest := s[i:]
+	if !strings.HasPrefix(rest, "::") ||
+		len(rest) > 2 && !isSpace(rune(rest[2])) {
+		return
+	}
+	return m, true
+}
+
+// isDirective returns true if the current position begins a directive.
+func isDirective(l *lexer) bool {
+	if l.lastItem != nil && l.lastItem.Type == itemTitle {
+		return false
+	}
+	_, ok := scanDirectiveMarker(l.currentLine()[l.index:])
+	return ok
+}
+
+// lexDirective emits a directive found by isDirective. On the marker line,
+// ".." and the "::" following the name are emitted as itemDirective, the name
+// as itemDirectiveName, and the text following "::" as an
+// itemDirectiveArgument. The block of the directive is lexed by
+// lexDirectiveBlock. Directives are lexed the same way whatever their name,
+// the arguments, options, and content a directive accepts are checked by the
+// parser.
+func lexDirective(l *lexer) stateFn {
+	log.Debugln("START")
+	column := l.indentOf(l.currentLine())
+	line := l.currentLine()
+	start := l.index
+	m, _ := scanDirectiveMarker(line[start:])
+	l.gotoLocation(start+2, l.lineNumber())
+	l.emit(itemDirective)
+	l.gotoLocation(start+m.nameStart, l.lineNumber())
+	l.emit(itemSpace)
+	l.gotoLocation(start+m.nameEnd, l.lineNumber())
+	l.emit(itemDirectiveName)
+	if m.delimStart > m.nameEnd {
+		l.gotoLocation(start+m.delimStart, l.lineNumber())
+		l.emit(itemSpace)
+	}
+	l.gotoLocation(start+m.delimStart+2, l.lineNumber())
+	l.emit(itemDirective)
+	if arg := strings.TrimLeftFunc(line[l.index:], isSpace); arg != "" {
+		l.gotoLocation(len(line)-len(arg), l.lineNumber())
+		l.emit(itemSpace)
+		l.gotoLocation(len(line), l.lineNumber())
+		l.emit(itemDirectiveArgument)
+	}
+	lexDirectiveBlock(l, column)
+	log.Debugln("END")
+	return lexStart
+}
+
+// lexDirectiveBlock emits the block of a directive whose marker is at column.
+// As in the body of a comment, the block contains the lines following the
+// marker line that are indented more than column, and the blank lines
+// between them.
+//
+// The block begins with the options of the directive, the lines directly
+// following the marker line that begin with a field marker such as ":alt:",
+// and the lines indented more than the options, which continue the value of
+// the option above them. The colons of the field marker are emitted as
+// itemDirectiveOption, the name as itemDirectiveOptionName, and the value as
+// itemDirectiveOptionValue. The rest of the block is the content of the
+// directive, emitted by lexIndentedLines as itemDirectiveContent, so the
+// content is kept as written and can be lexed again by the directive. The
+// blank lines of the block are emitted as itemBlankLine.
+func lexDirectiveBlock(l *lexer, column int) {
+	last := -1
+	for n := l.line + 1; n < len(l.lines); n++ {
+		if l.isBlankLine(n) {
+			continue
+		}
+		if l.indentOf(l.lines[n]) <= column {
+			break
+		}
+		last = n
+	}
+	l.nextLine()
+	if last == -1 {
+		return
+	}
+	n, optionIndent := l.line, -1
+	for ; n <= last && !l.isBlankLine(n); n++ {
+		line := l.currentLine()
+		w := l.indentOf(line)
+		text := strings.TrimLeftFunc(line, l.isIndentSpace)
+		start := len(line) - len(text)
+		if end := fieldMarkerEnd(text); end != -1 &&
+			(optionIndent == -1 || w == optionIndent) {
+			optionIndent = w
+			lexDirectiveOption(l, start, start+end)
+		} else if optionIndent != -1 && w > optionIndent {
+			l.gotoLocation(start, l.lineNumber())
+			l.emit(itemSpace)
+			l.gotoLocation(len(line), l.lineNumber())
+			l.emit(itemDirectiveOptionValue)
+		} else {
+			break
+		}
+		l.nextLine()
+	}
+	if n <= last {
+		lexIndentedLines(l, column, itemDirectiveContent)
+	}
+}
+
+// lexDirectiveOption emits the option of a directive on the current line,
+// whose field marker begins at start and ends with the colon at end.
+func lexDirectiveOption(l *lexer, start, end int) {
+	line := l.currentLine()
+	l.gotoLocation(start, l.lineNumber())
+	l.emit(itemSpace)
+	l.gotoLocation(start+1, l.lineNumber())
+	l.emit(itemDirectiveOption)
+	l.gotoLocation(end, l.lineNumber())
+	l.emit(itemDirectiveOptionName)
+	l.gotoLocation(end+1, l.lineNumber())
+	l.emit(itemDirectiveOption)
+	if value := strings.TrimLeftFunc(line[l.index:], isSpace); value != "" {
+		l.gotoLocation(len(line)-len(value), l.lineNumber())
+		l.emit(itemSpace)
+		l.gotoLocation(len(line), l.lineNumber())
+		l.emit(itemDirectiveOptionValue)
+	}
+}
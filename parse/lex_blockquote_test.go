@@ -0,0 +1,178 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import "testing"
+
+func TestLexBlockQuoteNoBlankLineBad0000(t *testing.T) {
+	// A block quote followed by unindented text without a blank line
+	testPath := testPathFromName("00.00-no-blank-line")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexBlockQuoteNoBlankLineInSectionBad0001(t *testing.T) {
+	// The warning for a block quote in a section is added to the section.
+	testPath := testPathFromName("00.01-no-blank-line-in-section")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexBlockQuoteUnexpectedIndentBad0100(t *testing.T) {
+	// Text indented from a paragraph of two lines, without a blank line.
+	testPath := testPathFromName("01.00-unexpected-indent")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexBlockQuoteUnexpectedIndentInBlockQuoteBad0101(t *testing.T) {
+	// Text indented further in a block quote, without a blank line.
+	testPath := testPathFromName("01.01-unexpected-indent-in-block-quote")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexBlockQuoteNBSPIndentText0000(t *testing.T) {
+	// A line indented with no-break spaces is lexed as a paragraph
+	testPath := testPathFromName("00.00-nbsp-indent-text")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexBlockQuoteTwoLevelsGood0100(t *testing.T) {
+	// Block quotes on two levels of indentation
+	testPath := testPathFromName("01.00-two-levels")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexBlockQuoteThreeLevelsAttributionGood0101(t *testing.T) {
+	// Three levels of block quotes with an attribution at level two
+	testPath := testPathFromName("01.01-three-levels-attribution")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexBlockQuoteUnicodeEmDashGood0200(t *testing.T) {
+	// Attributions beginning with an em dash and with "---".
+	testPath := testPathFromName("02.00-unicode-em-dash")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexBlockQuoteUnevenIndentsGood0300(t *testing.T) {
+	// An eight space block quote followed by a four space block quote
+	testPath := testPathFromName("03.00-uneven-indents")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexBlockQuoteAttributionGood0400(t *testing.T) {
+	// Block quotes ending with attributions
+	testPath := testPathFromName("04.00-para-bq-attrib")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexBlockQuoteTwoLineAttributionGood0401(t *testing.T) {
+	// Attributions continued on a second line
+	testPath := testPathFromName("04.01-para-bq-two-line-attrib")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexBlockQuoteAttributionNoSpaceGood0402(t *testing.T) {
+	// An attribution without a space after the dashes
+	testPath := testPathFromName("04.02-para-bq-attrib-no-space")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexBlockQuoteOneAttributionGood0403(t *testing.T) {
+	// Two block quotes with one attribution
+	testPath := testPathFromName("04.03-para-bq-one-attrib")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexBlockQuoteInvalidAttributionGood0404(t *testing.T) {
+	// Text beginning with "--" that is not an attribution
+	testPath := testPathFromName("04.04-para-bq-attrib-invalid")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexBlockQuoteInvalidAfterAttributionGood0405(t *testing.T) {
+	// Attributions following text that is not an attribution
+	testPath := testPathFromName("04.05-para-bq-attrib-with-invalid-attrib")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexBlockQuoteOnlyAttributionGood0406(t *testing.T) {
+	// A block quote containing only text beginning with "--".
+	testPath := testPathFromName("04.06-para-bq-only-attrib")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexBlockQuoteAttributionOutsideBlockQuoteGood0407(t *testing.T) {
+	// Text beginning with "--" in a list item and in a definition
+	testPath := testPathFromName("04.07-para-bq-attrib-outside-bq")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexBlockQuoteAttributionTermLinesGood0408(t *testing.T) {
+	// Text beginning with "--" in a definition, followed by two lines indented
+	// more, is a definition term and its definition.
+	testPath := testPathFromName("04.08-para-bq-attrib-term-lines")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexBlockQuoteSectionParagraphGood0500(t *testing.T) {
+	// A paragraph following a block quote in a section
+	testPath := testPathFromName("05.00-section-bq-paragraph")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexBlockQuoteFirstLineGood0600(t *testing.T) {
+	// An indented first line begins a block quote, as a line following
+	// a blank line does.
+	testPath := testPathFromName("06.00-bq-first-line")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexBlockQuoteFirstLineBulletGood0601(t *testing.T) {
+	// An indented bullet on the first line begins a list in a block
+	// quote.
+	testPath := testPathFromName("06.01-bq-first-line-bullet")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
@@ -0,0 +1,123 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import "testing"
+
+func TestLexBulletListDifferentBulletsGood0000(t *testing.T) {
+	// Two bullet lists using different bullets separated by a blank line
+	testPath := testPathFromName("00.00-different-bullets")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexBulletListItemStartsWithEmphasisGood0001(t *testing.T) {
+	// Only the bullet rune and the following space are inspected, so inline
+	// markup may begin the item body.
+	testPath := testPathFromName("00.01-item-starts-with-emphasis")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexBulletListItemStartsWithEscapeGood0002(t *testing.T) {
+	// An escaped asterisk beginning the item body is kept for the inline
+	// parser.
+	testPath := testPathFromName("00.02-item-starts-with-escape")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexBulletListItemStartsWithFlagLiteralGood0003(t *testing.T) {
+	// An inline literal option name beginning a two line item body.
+	testPath := testPathFromName("00.03-item-starts-with-flag-literal")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexBulletListItemOnlyInlineLiteralGood0004(t *testing.T) {
+	// An item body containing only an inline literal.
+	testPath := testPathFromName("00.04-item-only-inline-literal")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexBulletListItemOnlyFootnoteReferenceGood0005(t *testing.T) {
+	// An item body containing only a footnote reference.
+	testPath := testPathFromName("00.05-item-only-footnote-reference")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexBulletListEmphasisStartNotABulletGood0006(t *testing.T) {
+	// An asterisk followed by text is not a bullet.
+	testPath := testPathFromName("00.06-emphasis-start-not-a-bullet")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexBulletListBulletAtEOFGood0100(t *testing.T) {
+	// A bullet immediately followed by the end of input is an empty item.
+	testPath := testPathFromName("01.00-bullet-at-eof")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexBulletListBulletWithoutSpaceGood0101(t *testing.T) {
+	// A bullet rune that is not followed by a space begins a paragraph.
+	testPath := testPathFromName("01.01-bullet-without-space")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexBulletListNestedItemsGood0102(t *testing.T) {
+	// Items indented under the body of a parent item are lexed with their
+	// indentation as an itemSpace.
+	testPath := testPathFromName("01.02-nested-items")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexBulletListDefinitionListInItemGood0103(t *testing.T) {
+	// The term of a definition list in an item is lexed after the
+	// indentation of the item body.
+	testPath := testPathFromName("01.03-definition-list-in-item")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexBulletListNestedListsEndGood0104(t *testing.T) {
+	// Nested items are lexed with their indentation as an itemSpace.
+	testPath := testPathFromName("01.04-nested-lists-end")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexBulletListMixedBulletsMisalignedBodyBad0000(t *testing.T) {
+	// A change of bullet and a misaligned item body without blank lines
+	testPath := testPathFromName("00.00-mixed-bullets-misaligned-body")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexBulletListParagraphAfterListBad0001(t *testing.T) {
+	// A paragraph following a bullet list without a blank line
+	testPath := testPathFromName("00.01-paragraph-after-list-no-blank-line")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
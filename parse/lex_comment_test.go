@@ -55,6 +55,22 @@ func TestLexTwoCommentsNoBlankLineBad0001(t *testing.T) {
 	equal(t, test.expectItems(), items)
 }
 
+func TestLexEmptyCommentInBulletItemBad0102(t *testing.T) {
+	// An empty comment in a list item directly followed by a nested list
+	testPath := testPathFromName("01.02-empty-comment-in-bullet-item")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexEmptyCommentInBlockQuoteBad0103(t *testing.T) {
+	// An empty comment in a block quote directly followed by a list
+	testPath := testPathFromName("01.03-empty-comment-in-block-quote")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
 func TestLexCommentWithLiteralMarkGood0002(t *testing.T) {
 	// A comment ending with a literal block mark.
 	testPath := testPathFromName("00.02-comment-with-literal-mark")
@@ -142,3 +158,53 @@ func TestLexCommentTrailingBulletGood0502(t *testing.T) {
 	items := lexTest(t, test)
 	equal(t, test.expectItems(), items)
 }
+
+func TestLexCommentLicenseFrontMatterGood0700(t *testing.T) {
+	// A license comment before the document title.
+	testPath := testPathFromName("07.00-license-front-matter")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexCommentBodyNoBlankLineBad0200(t *testing.T) {
+	// A comment body ending without a blank line before a paragraph.
+	testPath := testPathFromName("02.00-comment-body-no-blankline")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexCommentBodyWithBlankLinesGood0800(t *testing.T) {
+	// The blank lines between the indented lines of a comment are part of
+	// the comment.
+	testPath := testPathFromName("08.00-comment-body-with-blank-lines")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexCommentBodyIndentationKeptGood0801(t *testing.T) {
+	// The lines of a comment body are kept as written, with any further
+	// indentation, and do not begin other elements.
+	testPath := testPathFromName("08.01-comment-body-indentation-kept")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexCommentBodyAfterMarkerLineGood0802(t *testing.T) {
+	// A comment with blank lines beginning on the line after the marker.
+	testPath := testPathFromName("08.02-comment-body-after-marker-line")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexEmptyCommentEndsBlockQuoteGood0803(t *testing.T) {
+	// An empty comment between two block quotes keeps them apart.
+	testPath := testPathFromName("08.03-empty-comment-ends-block-quote")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
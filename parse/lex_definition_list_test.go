@@ -0,0 +1,66 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import "testing"
+
+func TestLexDefinitionListNoBlankLineBad0000(t *testing.T) {
+	// A definition list followed by a paragraph without a blank line
+	testPath := testPathFromName("00.00-def-list-no-blank-line")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexDefinitionListClassifiersGood0100(t *testing.T) {
+	// Classifiers follow the term, separated by " : ".
+	testPath := testPathFromName("01.00-classifiers")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexDefinitionListItemsWithoutBlankLineGood0101(t *testing.T) {
+	// The term of the second item directly follows the first definition.
+	testPath := testPathFromName("01.01-items-without-blank-line")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexDefinitionListNestedDefinitionListGood0102(t *testing.T) {
+	// The first line of a definition is the term of a nested definition
+	// list.
+	testPath := testPathFromName("01.02-nested-definition-list")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexDefinitionListBlockQuoteAfterBlankLineGood0103(t *testing.T) {
+	// A blank line between a line and an indented line makes the indented
+	// line a block quote, not a definition.
+	testPath := testPathFromName("01.03-block-quote-after-blank-line")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexDefinitionListEmptyBulletBetweenListsGood0104(t *testing.T) {
+	// A bullet alone on a line between two definition lists is lexed as
+	// an itemBullet.
+	testPath := testPathFromName("01.04-empty-bullet-between-lists")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexDefinitionListTermAfterBlockQuoteGood0105(t *testing.T) {
+	// A term that is not indented after a block quote.
+	testPath := testPathFromName("01.05-term-after-block-quote")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
@@ -0,0 +1,87 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import "testing"
+
+func TestLexDegenerateEmptyInput0000(t *testing.T) {
+	// An empty input
+	testPath := testPathFromName("00.00-empty-input")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexDegenerateSingleNewline0001(t *testing.T) {
+	// A single newline
+	testPath := testPathFromName("00.01-single-newline")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexDegenerateOnlyBlankLines0002(t *testing.T) {
+	// Only blank lines
+	testPath := testPathFromName("00.02-only-blank-lines")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexDegenerateOnlySpaces0003(t *testing.T) {
+	// Only spaces, without a newline
+	testPath := testPathFromName("00.03-only-spaces")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexDegenerateSpacesAndNewline0004(t *testing.T) {
+	// A line of spaces followed by a newline
+	testPath := testPathFromName("00.04-spaces-and-newline")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexDegenerateWhitespaceLines0005(t *testing.T) {
+	// Lines containing only spaces and tabs
+	testPath := testPathFromName("00.05-whitespace-lines")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexDegenerateSingleAdornmentRune0100(t *testing.T) {
+	// A single adornment rune is a paragraph
+	testPath := testPathFromName("01.00-single-adornment-rune")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexDegenerateShortAdornmentLine0101(t *testing.T) {
+	// An adornment line too short to be a transition is a paragraph
+	testPath := testPathFromName("01.01-short-adornment-line")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexDegenerateLoneLiteralMarker0102(t *testing.T) {
+	// A lone "::" is a paragraph, not a transition
+	testPath := testPathFromName("01.02-lone-literal-marker")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexDegenerateSingleMultibyteRune0103(t *testing.T) {
+	// A single multibyte rune
+	testPath := testPathFromName("01.03-single-multibyte-rune")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
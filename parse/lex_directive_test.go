@@ -0,0 +1,104 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import "testing"
+
+func TestLexDirectiveImageGood0000(t *testing.T) {
+	// An image directive with an argument and options, one of which is
+	// continued on the next line.
+	testPath := testPathFromName("00.00-directive-image")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexDirectiveNoteGood0001(t *testing.T) {
+	// A note directive with content beginning on the marker line.
+	testPath := testPathFromName("00.01-directive-note")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexDirectiveOptionsOnlyGood0002(t *testing.T) {
+	// A directive with options and no content.
+	testPath := testPathFromName("00.02-directive-options-only")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexDirectiveNoContentGood0003(t *testing.T) {
+	// Directives with no arguments, options, or content.
+	testPath := testPathFromName("00.03-directive-no-content")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexDirectiveUnknownNameGood0004(t *testing.T) {
+	// A directive with a name that is not known is lexed as any other.
+	testPath := testPathFromName("00.04-directive-unknown-name")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexDirectiveNoBlankLineBad0000(t *testing.T) {
+	// A directive followed by a paragraph without a blank line.
+	testPath := testPathFromName("00.00-directive-no-blankline")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexDirectiveCodeDirectiveGood0100(t *testing.T) {
+	// A code directive with blank lines, tabs, and lines starting "..".
+	testPath := testPathFromName("01.00-code-directive")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexDirectiveCodeBlockLinenosGood0101(t *testing.T) {
+	// A code-block directive with the lines numbered from one.
+	testPath := testPathFromName("01.01-code-block-linenos")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexDirectiveCodeNumberLinesStartGood0102(t *testing.T) {
+	// A code directive with the lines numbered from a given line.
+	testPath := testPathFromName("01.02-code-number-lines-start")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexDirectiveCodeWithoutLanguageGood0103(t *testing.T) {
+	// A code directive without a language argument.
+	testPath := testPathFromName("01.03-code-without-language")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexDirectiveCodeWithoutContentBad0100(t *testing.T) {
+	// A code directive without content.
+	testPath := testPathFromName("01.00-code-without-content")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexDirectiveCodeInvalidNumberLinesBad0101(t *testing.T) {
+	// A code directive with a :number-lines: value that is not a number.
+	testPath := testPathFromName("01.01-code-invalid-number-lines")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
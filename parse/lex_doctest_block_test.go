@@ -0,0 +1,47 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import "testing"
+
+func TestLexDoctestBlockGood0000(t *testing.T) {
+	// A doctest block followed by a paragraph.
+	testPath := testPathFromName("00.00-doctest-block")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexDoctestBlockMultiStatementSessionGood0001(t *testing.T) {
+	// A session with several statements, continuation lines, and output.
+	testPath := testPathFromName("00.01-multi-statement-session")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexDoctestBlockInBulletListGood0002(t *testing.T) {
+	// A doctest block in the body of a bullet list item.
+	testPath := testPathFromName("00.02-doctest-block-in-bullet-list")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexDoctestBlockQuotedLiteralBlockPromptGood0003(t *testing.T) {
+	// Lines beginning with ">>>" after "::" are a quoted literal block.
+	testPath := testPathFromName("00.03-quoted-literal-block-prompt")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexDoctestBlockBelowOverlineBad0000(t *testing.T) {
+	// A doctest line directly below an overline is title text.
+	testPath := testPathFromName("00.00-doctest-below-overline")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
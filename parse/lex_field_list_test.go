@@ -0,0 +1,66 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import "testing"
+
+func TestLexFieldListNoBlankLineBad0000(t *testing.T) {
+	// A field list followed by a paragraph without a blank line
+	testPath := testPathFromName("00.00-field-list-no-blank-line")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexFieldListNotAFieldMarkerBad0001(t *testing.T) {
+	// Lines that begin with a colon but not with a field marker are
+	// paragraphs.
+	testPath := testPathFromName("00.01-not-a-field-marker")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexFieldListGood0000(t *testing.T) {
+	// Two fields with one line bodies.
+	testPath := testPathFromName("00.00-field-list")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexFieldListEmptyFieldBodyGood0001(t *testing.T) {
+	// Fields without a body, followed by a field with a body.
+	testPath := testPathFromName("00.01-empty-field-body")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexFieldListEscapedColonInNameGood0002(t *testing.T) {
+	// A colon escaped with a backslash, or followed by text, is part of
+	// the field name.
+	testPath := testPathFromName("00.02-escaped-colon-in-name")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexFieldListMultiLineFieldBodyGood0003(t *testing.T) {
+	// The field body continues on the lines indented more than the field
+	// marker, including after a blank line.
+	testPath := testPathFromName("00.03-multi-line-field-body")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexFieldListBelowOverlineBad0002(t *testing.T) {
+	// A field directly below an overline is title text.
+	testPath := testPathFromName("00.02-field-below-overline")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
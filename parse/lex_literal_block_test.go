@@ -0,0 +1,103 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import "testing"
+
+func TestLexLiteralBlockNoBlankLineBad0000(t *testing.T) {
+	// A literal block followed by a paragraph without a blank line.
+	testPath := testPathFromName("00.00-literal-block-no-blank-line")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexLiteralBlockExpectedBad0001(t *testing.T) {
+	// A paragraph ending with "::" and no indented block after it.
+	testPath := testPathFromName("00.01-literal-block-expected")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexLiteralBlockInconsistentQuotingBad0002(t *testing.T) {
+	// A quoted literal block ended by a line with a different quote.
+	testPath := testPathFromName("00.02-inconsistent-literal-block-quoting")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexLiteralBlockGood0000(t *testing.T) {
+	// A lone "::" paragraph introducing a literal block.
+	testPath := testPathFromName("00.00-expanded-form")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexLiteralBlockMinimizedFormGood0001(t *testing.T) {
+	// A "::" after a space, which is removed from the paragraph.
+	testPath := testPathFromName("00.01-minimized-form")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexLiteralBlockFullyMinimizedFormGood0002(t *testing.T) {
+	// A "::" after text, which is left as a single colon.
+	testPath := testPathFromName("00.02-fully-minimized-form")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexLiteralBlockAtEOFGood0003(t *testing.T) {
+	// A literal block ending the input.
+	testPath := testPathFromName("00.03-literal-block-at-eof")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexLiteralBlockInBulletListGood0004(t *testing.T) {
+	// A literal block in the body of a bullet list item.
+	testPath := testPathFromName("00.04-literal-block-in-bullet-list")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexLiteralBlockInDefinitionGood0005(t *testing.T) {
+	// A literal block in the definition of a definition list item.
+	testPath := testPathFromName("00.05-literal-block-in-definition")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexLiteralBlockEscapedMarkerGood0006(t *testing.T) {
+	// An escaped "::" does not introduce a literal block.
+	testPath := testPathFromName("00.06-escaped-literal-marker")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexLiteralBlockQuotedGood0007(t *testing.T) {
+	// A literal block quoted with ">", which is kept in the text.
+	testPath := testPathFromName("00.07-quoted-literal-block")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexLiteralBlockQuotedVerticalBarGood0008(t *testing.T) {
+	// Literal blocks quoted with "|".
+	testPath := testPathFromName("00.08-quoted-literal-block-vertical-bar")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
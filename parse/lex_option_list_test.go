@@ -0,0 +1,88 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import "testing"
+
+func TestLexOptionListNoBlankLineBad0000(t *testing.T) {
+	// An option list followed by a paragraph without a blank line
+	testPath := testPathFromName("00.00-option-list-no-blank-line")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexOptionListNotAnOptionListBad0001(t *testing.T) {
+	// Lines that begin like options but have no description, or have text
+	// after a single space, are paragraphs.
+	testPath := testPathFromName("00.01-not-an-option-list")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexOptionListGood0000(t *testing.T) {
+	// Short options with and without arguments.
+	testPath := testPathFromName("00.00-short-options")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexOptionListLongOptionsGood0001(t *testing.T) {
+	// Long options with "=" and space delimited arguments.
+	testPath := testPathFromName("00.01-long-options")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexOptionListDosVmsOptionsGood0002(t *testing.T) {
+	// Old style Unix options and DOS/VMS options.
+	testPath := testPathFromName("00.02-dos-vms-options")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexOptionListOptionSynonymsGood0003(t *testing.T) {
+	// Option groups with synonyms separated by ", ".
+	testPath := testPathFromName("00.03-option-synonyms")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexOptionListDescriptionOnNextLineGood0004(t *testing.T) {
+	// Descriptions beginning on the line after the option group.
+	testPath := testPathFromName("00.04-description-on-next-line")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexOptionListMultiLineDescriptionGood0005(t *testing.T) {
+	// Descriptions continuing on indented lines and after blank lines.
+	testPath := testPathFromName("00.05-multi-line-description")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexOptionListAngleBracketArgumentGood0006(t *testing.T) {
+	// Arguments enclosed in angle brackets, which may contain spaces.
+	testPath := testPathFromName("00.06-angle-bracket-argument")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexOptionListBelowOverlineBad0002(t *testing.T) {
+	// An option directly below an overline is title text.
+	testPath := testPathFromName("00.02-option-below-overline")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
@@ -45,3 +45,19 @@ func TestLexTwoSeparateThreeLineParagraphs0101(t *testing.T) {
 	items := lexTest(t, test)
 	equal(t, test.expectItems(), items)
 }
+
+func TestLexTwoParagraphsWhitespaceLine0102(t *testing.T) {
+	// Two paragraphs separated by a line containing only spaces
+	testPath := testPathFromName("01.02-two-para-whitespace-line")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexParagraphInvisibleControl0200(t *testing.T) {
+	// A right-to-left override in the display text of a hyperlink
+	testPath := testPathFromName("02.00-rtl-override-link-text")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
@@ -57,6 +57,24 @@ func TestLexSectionTitleGood0300(t *testing.T) {
 	equal(t, test.expectItems(), items)
 }
 
+func TestLexSectionTitleGood0400(t *testing.T) {
+	// A title underline followed by trailing spaces. The spaces are not
+	// part of the underline.
+	testPath := testPathFromName("04.00-underline-trailing-space")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexSectionTitleGood0500(t *testing.T) {
+	// A short adornment line directly below the underline of a title is
+	// paragraph text.
+	testPath := testPathFromName("05.00-short-adornment-below-title")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
 func TestLexSectionTitleBad0000(t *testing.T) {
 	// Tests for severe system messages when the sections are indented.
 	testPath := testPathFromName("00.00-unexpected-titles")
@@ -99,6 +117,24 @@ func TestLexSectionTitleBad0202(t *testing.T) {
 	equal(t, test.expectItems(), items)
 }
 
+func TestLexSectionTitleBad0300(t *testing.T) {
+	// A title "underline" that mixes adornment characters. Since it is
+	// not an adornment, the title and underline are a paragraph.
+	testPath := testPathFromName("03.00-mixed-underline")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexSectionTitleBad0301(t *testing.T) {
+	// A title "underline" of alternating adornment characters, which is
+	// also paragraph text.
+	testPath := testPathFromName("03.01-alternating-underline")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
 func TestLexSectionLevelGood0000(t *testing.T) {
 	// Tests section level return to level one after three subsections.
 	testPath := testPathFromName("00.00-section-level-return")
@@ -141,6 +177,16 @@ func TestLexSectionLevelGood0200(t *testing.T) {
 	equal(t, test.expectItems(), items)
 }
 
+func TestLexSectionLevelGood0300(t *testing.T) {
+	// A document title with an overline followed by sections using the
+	// same rune without an overline. These are different section styles,
+	// so the sections are level two.
+	testPath := testPathFromName("03.00-overline-title-same-rune-sections")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
 func TestLexSectionLevelBad0000(t *testing.T) {
 	// Test section level return on bad level 2 section adornment
 	testPath := testPathFromName("00.00-bad-subsection-order")
@@ -167,6 +213,15 @@ func TestLexSectionLevelBad0100(t *testing.T) {
 	equal(t, test.expectItems(), items)
 }
 
+func TestLexSectionLevelBad0200(t *testing.T) {
+	// A section using the style of level three directly below a level one
+	// section is inconsistent.
+	testPath := testPathFromName("02.00-existing-level-too-deep")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
 func TestLexSectionTitleWithOverlineGood0000(t *testing.T) {
 	// Test simple section with title overline.
 	testPath := testPathFromName("00.00-title-overline")
@@ -191,6 +246,15 @@ func TestLexSectionTitleWithOverlineGood0200(t *testing.T) {
 	equal(t, test.expectItems(), items)
 }
 
+func TestLexSectionTitleWithOverlineGood0300(t *testing.T) {
+	// A title that begins and ends with the adornment rune of its
+	// overline and underline.
+	testPath := testPathFromName("03.00-adornment-rune-title")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
 func TestLexSectionTitleWithOverlineBad0000(t *testing.T) {
 	// Test section title with overline, but no underline.
 	testPath := testPathFromName("00.00-inset-title-missing-underline")
@@ -274,6 +338,15 @@ func TestLexSectionTitleWithOverlineBad0400(t *testing.T) {
 	equal(t, test.expectItems(), items)
 }
 
+func TestLexSectionTitleWithOverlineBad0401(t *testing.T) {
+	// An indented title with a short overline and an underline that is
+	// not indented.
+	testPath := testPathFromName("04.01-indented-title-unindented-underline")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
 func TestLexSectionTitleWithOverlineBad0500(t *testing.T) {
 	// Tests ".." overline (which is a comment element).
 	testPath := testPathFromName("05.00-two-char-section-title")
@@ -282,6 +355,54 @@ func TestLexSectionTitleWithOverlineBad0500(t *testing.T) {
 	equal(t, test.expectItems(), items)
 }
 
+func TestLexSectionTitleWithOverlineBad0600(t *testing.T) {
+	// An overline and a title at the end of the input.
+	testPath := testPathFromName("06.00-overline-title-at-end")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexSectionTitleWithOverlineBad0601(t *testing.T) {
+	// An overline and a title followed by a blank line.
+	testPath := testPathFromName("06.01-overline-title-blank-line")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexSectionTitleWithOverlineBad0602(t *testing.T) {
+	// An overline and a title followed by a line of text.
+	testPath := testPathFromName("06.02-overline-title-text-line")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexSectionTitleWithOverlineBad0603(t *testing.T) {
+	// An overline and a title followed by an indented comment marker.
+	testPath := testPathFromName("06.03-overline-title-indented-comment")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexSectionTitleWithOverlineBad0604(t *testing.T) {
+	// An overline and an inset title followed by an indented comment marker.
+	testPath := testPathFromName("06.04-overline-inset-title-indented-comment")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexSectionTitleWithOverlineBad0605(t *testing.T) {
+	// An overline and a title followed by an indented comment with text.
+	testPath := testPathFromName("06.05-overline-title-indented-comment-text")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
 func TestLexSectionTitleNumberedGood0000(t *testing.T) {
 	// Tests lexing a section where the title begins with a number.
 	testPath := testPathFromName("00.00-numbered-title")
@@ -297,3 +418,94 @@ func TestLexSectionTitleNumberedGood0100(t *testing.T) {
 	items := lexTest(t, test)
 	equal(t, test.expectItems(), items)
 }
+
+func TestLexSectionMaxDepthGood0000(t *testing.T) {
+	// Tests lexing six levels of nested sections.
+	testPath := testPathFromName("00.00-six-levels-no-limit")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexSectionBlankSeparatedAdornmentGood0000(t *testing.T) {
+	// An adornment line between blank lines is a transition.
+	testPath := testPathFromName("00.00-blank-separated-transition")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexSectionBlankSeparatedAdornmentGood0001(t *testing.T) {
+	// An adornment line directly below text is an underline.
+	testPath := testPathFromName("00.01-blank-separated-underline")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexSectionBlankSeparatedAdornmentGood0002(t *testing.T) {
+	// An adornment line after a blank line and above text is an overline.
+	testPath := testPathFromName("00.02-blank-separated-overline")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexSectionTitleLeadingSpaceGood0000(t *testing.T) {
+	// A no-break space is not indentation by default, it begins the
+	// title text.
+	testPath := testPathFromName("00.00-nbsp-title")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexSectionTitleLeadingSpaceGood0100(t *testing.T) {
+	// Spaces that are never indentation, such as U+3000 and U+2003, begin
+	// the title text.
+	testPath := testPathFromName("01.00-wide-space-title")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexSectionTitleInvisibleControlGood0000(t *testing.T) {
+	// A title can begin with a bidi override or a zero-width space.
+	testPath := testPathFromName("00.00-invisible-control-title")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexSectionBlankSeparatedAdornmentBad0000(t *testing.T) {
+	// The line below an overline is text, even if it reads as a bullet
+	// list item.
+	testPath := testPathFromName("00.00-overline-bullet-item")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexSectionBlankSeparatedAdornmentBad0001(t *testing.T) {
+	// The line below an overline is text, even if it reads as a comment.
+	testPath := testPathFromName("00.01-overline-comment")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexSectionBlankSeparatedAdornmentBad0002(t *testing.T) {
+	// The line below an overline is text, even if it reads as a directive.
+	testPath := testPathFromName("00.02-overline-directive")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexSectionBlankSeparatedAdornmentBad0100(t *testing.T) {
+	// A short overline followed by a bullet list item.
+	testPath := testPathFromName("01.00-short-overline-bullet-item")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
@@ -5,15 +5,21 @@
 package parse
 
 import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/json"
 	"fmt"
+	"io/fs"
 	"reflect"
 	"strconv"
+	"strings"
 	"testing"
 	"unicode/utf8"
 
-	"code.google.com/p/go.text/unicode/norm"
+	"golang.org/x/text/unicode/norm"
 
 	"github.com/demizer/go-elog"
+	"github.com/demizer/go-rst/internal/corpus"
 )
 
 var (
@@ -145,7 +151,7 @@ var lexerTests = []struct {
 
 func TestLexerNew(t *testing.T) {
 	for _, tt := range lexerTests {
-		lex := newLexer(tt.name, tt.input)
+		lex := newLexer(tt.name, tt.input, false)
 		if lex.index != tt.nIndex {
 			t.Errorf("Test: %q\n\t   "+
 				"Got: lexer.index == %d, Expect: %d\n\n",
@@ -195,7 +201,7 @@ var lexerGotoLocationTests = []struct {
 
 func TestLexerGotoLocation(t *testing.T) {
 	for _, tt := range lexerGotoLocationTests {
-		lex := newLexer(tt.name, tt.input)
+		lex := newLexer(tt.name, tt.input, false)
 		lex.gotoLocation(tt.start, tt.startLine)
 		if lex.index != tt.lIndex {
 			t.Errorf("Test: %q\n\t    "+
@@ -291,7 +297,7 @@ var lexerBackupTests = []struct {
 
 func TestLexerBackup(t *testing.T) {
 	for _, tt := range lexerBackupTests {
-		lex := newLexer(tt.name, tt.input)
+		lex := newLexer(tt.name, tt.input, false)
 		lex.gotoLocation(tt.start, tt.startLine)
 		lex.backup(tt.pos)
 		if lex.index != tt.lIndex {
@@ -385,7 +391,7 @@ var lexerNextTests = []struct {
 
 func TestLexerNext(t *testing.T) {
 	for _, tt := range lexerNextTests {
-		lex := newLexer(tt.name, tt.input)
+		lex := newLexer(tt.name, tt.input, false)
 		lex.gotoLocation(tt.start, tt.startLine)
 		r, w := lex.next()
 		if lex.index != tt.nIndex {
@@ -469,7 +475,7 @@ var lexerPeekTests = []struct {
 
 func TestLexerPeek(t *testing.T) {
 	for _, tt := range lexerPeekTests {
-		lex := newLexer(tt.name, tt.input)
+		lex := newLexer(tt.name, tt.input, false)
 		lex.gotoLocation(tt.start, tt.startLine)
 		r := lex.peek()
 		w := utf8.RuneLen(r)
@@ -503,21 +509,21 @@ func TestLexerPeek(t *testing.T) {
 
 func TestLexerIsLastLine(t *testing.T) {
 	input := "==============\nTitle\n=============="
-	lex := newLexer("isLastLine test 1", input)
+	lex := newLexer("isLastLine test 1", input, false)
 	lex.gotoLocation(0, 1)
 	if lex.isLastLine() != false {
 		t.Errorf("Test: %q\n\t    "+
 			"Got: isLastLine == %t, Expect: %t\n\n",
 			lex.name, lex.isLastLine(), false)
 	}
-	lex = newLexer("isLastLine test 2", input)
+	lex = newLexer("isLastLine test 2", input, false)
 	lex.gotoLocation(0, 2)
 	if lex.isLastLine() != false {
 		t.Errorf("Test: %q\n\t    "+
 			"Got: isLastLine == %t, Expect: %t\n\n",
 			lex.name, lex.isLastLine(), false)
 	}
-	lex = newLexer("isLastLine test 3", input)
+	lex = newLexer("isLastLine test 3", input, false)
 	lex.gotoLocation(0, 3)
 	if lex.isLastLine() != true {
 		t.Errorf("Test: %q\n\t    "+
@@ -569,7 +575,7 @@ var peekNextLineTests = []struct {
 
 func TestLexerPeekNextLine(t *testing.T) {
 	for _, tt := range peekNextLineTests {
-		lex := newLexer(tt.name, tt.input)
+		lex := newLexer(tt.name, tt.input, false)
 		lex.gotoLocation(tt.start, tt.startLine)
 		out := lex.peekNextLine()
 		if lex.index != tt.lIndex {
@@ -625,3 +631,437 @@ func TestLexStartPosition(t *testing.T) {
 		t.Error(`String StartPosition != "1"`)
 	}
 }
+
+// checkItemSpans checks that the items lexed from input account for the
+// whole input. itemEOF must be the last item and located at the end of the
+// input, before the trailing whitespace removed by the lexer. Every other
+// item must be found in the input at its position, and the items must not
+// overlap. An itemBlankLine spans the newline of an empty line. The input not
+// covered by an item must be whitespace.
+func checkItemSpans(t *testing.T, name, input string, items []item) {
+	input = whitespaceReplacer.Replace(norm.NFC.String(input))
+	lines := strings.Split(input, "\n")
+	if len(items) == 0 || items[len(items)-1].Type != itemEOF {
+		t.Errorf("%s: Last item is not itemEOF", name)
+		return
+	}
+	eof := items[len(items)-1]
+	end := len(strings.TrimRight(lines[len(lines)-1], " \t\r")) + 1
+	if int(eof.Line) != len(lines) || int(eof.StartPosition) != end {
+		t.Errorf("%s: itemEOF at line %d, position %d\n\t Expect: "+
+			"line %d, position %d", name, eof.Line, eof.StartPosition,
+			len(lines), end)
+	}
+
+	// The offset of each line in the input
+	offsets := make([]int, len(lines))
+	for i := 1; i < len(lines); i++ {
+		offsets[i] = offsets[i-1] + len(lines[i-1]) + 1
+	}
+	covered := make([]bool, len(input))
+	for _, i := range items[:len(items)-1] {
+		if int(i.Line) < 1 || int(i.Line) > len(lines) {
+			t.Errorf("%s: item ID=%d: line %d out of range", name, i.ID,
+				i.Line)
+			continue
+		}
+		line := lines[i.Line-1]
+		start := int(i.StartPosition) - 1
+		end := start + len(i.Text)
+		if i.Type == itemBlankLine {
+			// The newline of a line containing only whitespace
+			if strings.TrimSpace(line) != "" || int(i.Line) == len(lines) {
+				t.Errorf("%s: item ID=%d: itemBlankLine on line %d "+
+					"does not end a blank line", name, i.ID, i.Line)
+				continue
+			}
+		} else if start < 0 || end > len(line) || line[start:end] != i.Text {
+			t.Errorf("%s: item ID=%d: %q not found at line %d, "+
+				"position %d", name, i.ID, i.Text, i.Line,
+				i.StartPosition)
+			continue
+		}
+		if i.Length != utf8.RuneCountInString(i.Text) {
+			t.Errorf("%s: item ID=%d: Length %d != rune count of %q",
+				name, i.ID, i.Length, i.Text)
+		}
+		for o := offsets[i.Line-1] + start; o < offsets[i.Line-1]+end; o++ {
+			if covered[o] {
+				t.Errorf("%s: item ID=%d overlaps input offset %d",
+					name, i.ID, o)
+				break
+			}
+			covered[o] = true
+		}
+	}
+	for o, c := range covered {
+		if !c && !isSpace(rune(input[o])) && input[o] != '\n' {
+			t.Errorf("%s: input offset %d (%q) is not part of an item",
+				name, o, input[o])
+			break
+		}
+	}
+}
+
+// lexAll returns the items lexed from input.
+func lexAll(input string) (items []item) {
+	l := lex("lexAll", input)
+	for {
+		i := l.nextItem()
+		items = append(items, *i)
+		if i.Type == itemEOF || i.Type == itemError {
+			return
+		}
+	}
+}
+
+func TestLexEOF(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		eof   item
+	}{
+		{"no newline", "Paragraph.", item{Line: 1, StartPosition: 11}},
+		{"one newline", "Paragraph.\n", item{Line: 2, StartPosition: 1}},
+		{"blank lines", "Paragraph.\n\n\n", item{Line: 4, StartPosition: 1}},
+		{"lone adornment", "=====", item{Line: 1, StartPosition: 6}},
+		{"lone adornment newline", "=====\n",
+			item{Line: 2, StartPosition: 1}},
+		{"section no newline", "Title\n=====",
+			item{Line: 2, StartPosition: 6}},
+		{"indented no newline", "    Quote.",
+			item{Line: 1, StartPosition: 11}},
+		{"definition no newline", "Term\n    Definition.",
+			item{Line: 2, StartPosition: 16}},
+	}
+	for _, test := range tests {
+		items := lexAll(test.input)
+		eof := items[len(items)-1]
+		if eof.Line != test.eof.Line ||
+			eof.StartPosition != test.eof.StartPosition {
+			t.Errorf("%s: itemEOF at line %d, position %d\n\t Expect: "+
+				"line %d, position %d", test.name, eof.Line,
+				eof.StartPosition, test.eof.Line,
+				test.eof.StartPosition)
+		}
+		checkItemSpans(t, test.name, test.input, items)
+	}
+}
+
+func TestLexItemSpans(t *testing.T) {
+	paths, err := fs.Glob(corpus.Files, "*/*/*.rst")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range paths {
+		data, err := corpus.Files.ReadFile(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		input := string(data)
+		checkItemSpans(t, p, input, lexAll(input))
+		input = strings.TrimRight(input, "\n")
+		checkItemSpans(t, p+" (no newline)", input, lexAll(input))
+	}
+}
+
+func TestLexProgressCheck(t *testing.T) {
+	// A state function that does not consume input
+	var stall stateFn
+	stall = func(l *lexer) stateFn { return stall }
+	l := newLexer("stall", "Paragraph.\n", false)
+	l.state = stall
+	i := l.nextItem()
+	if i == nil || i.Type != itemError ||
+		i.Text != "lexer made no progress at line 1" {
+		t.Fatalf("Got: %v, Expect: itemError", i)
+	}
+	if i := l.nextItem(); i != nil {
+		t.Errorf("Expected the lexing to end, got: %v", i)
+	}
+}
+
+// lineClassTests are ambiguous lines in the contexts that decide their
+// classification. line is the line of the input that is checked, and expect is
+// the type of the first item lexed on it, indentation aside. The expected
+// classifications are those of docutils.
+var lineClassTests = []struct {
+	name   string
+	input  string
+	line   Line
+	expect itemElement
+}{
+	{"dashes first line", "----\n\nPara.\n", 1, itemTransition},
+	{"dashes between paragraphs", "Para.\n\n----\n\nPara.\n", 3,
+		itemTransition},
+	{"dashes underline", "Title\n-----\n", 2, itemSectionAdornment},
+	{"dashes overline", "-----\nTitle\n-----\n", 1, itemSectionAdornment},
+	{"dashes below paragraph", "Para.\n----\nMore.\n", 2,
+		itemSectionAdornment},
+	{"dashes above text", "Para.\n\n----\nText\n\nMore.\n", 3,
+		itemSectionAdornment},
+	{"dashes above text at end", "Para.\n\n----\nText\n", 3,
+		itemSectionAdornment},
+	{"dashes after bullet list", "* item\n\n----\n\nPara.\n", 3,
+		itemTransition},
+	{"option", "--option  Description.\n", 1, itemOption},
+	{"option without description", "--option\n", 1, itemParagraph},
+	{"not an option", "-1 is negative.\n", 1, itemParagraph},
+	{"doctest", ">>> 1 + 1\n2\n", 1, itemDoctestBlock},
+	{"doctest output", ">>> 1 + 1\n2\n", 2, itemDoctestBlock},
+	{"prompt without space", ">>>1\n", 1, itemParagraph},
+	{"doctest below paragraph", "Para.\n>>> 1\n", 2, itemParagraph},
+	{"quoted literal block prompt", "Para::\n\n>>> 1\n", 3,
+		itemLiteralBlock},
+	{"attribution dashes", "-- Not an attribution.\n", 1, itemParagraph},
+	{"short dashes", "--\n\nPara.\n", 1, itemParagraph},
+	{"asterisks between paragraphs", "Para.\n\n*****\n\nPara.\n", 3,
+		itemTransition},
+	{"asterisks underline", "Title\n*****\n", 2, itemSectionAdornment},
+	{"spaced asterisks", "* * *\n", 1, itemBullet},
+	{"spaced dashes", "- - - -\n", 1, itemBullet},
+	{"bullet", "* item\n", 1, itemBullet},
+	{"lone bullet", "-\n", 1, itemBullet},
+	{"lone bullet in list", "- a\n-\n- b\n", 2, itemBullet},
+	{"bullet below paragraph", "Para.\n* item\n", 2, itemParagraph},
+	{"bullet below bullet", "* item\n* item\n", 2, itemBullet},
+	{"enumerator below paragraph", "Para.\n1. item\n", 2, itemParagraph},
+	{"comment below paragraph", "Para.\n.. text\n", 2, itemParagraph},
+	{"nested bullet below item", "* item\n  * text\n", 2, itemParagraph},
+	{"short underline below paragraph", "Title\n-\n", 2, itemParagraph},
+	{"grid table border", "+----+\n", 1, itemParagraph},
+	{"short line below indented text", "  indented\n=\n", 2,
+		itemParagraph},
+	{"short line below enumerator", "1. x\n=\n", 2, itemParagraph},
+	{"dots", "....\n\nPara.\n", 1, itemTransition},
+	{"directive", ".. note:: Text.\n", 1, itemDirective},
+	{"directive without arguments", ".. contents::\n", 1, itemDirective},
+	{"directive space before delimiter", ".. image ::\n", 1, itemDirective},
+	{"directive with hyphen", ".. code-block:: go\n", 1, itemDirective},
+	{"directive with namespace", ".. py:function:: f\n", 1,
+		itemDirective},
+	{"directive without space after delimiter", ".. note::Text.\n", 1,
+		itemCommentMark},
+	{"substitution definition", ".. |name| image:: a.png\n", 1,
+		itemCommentMark},
+	{"directive below paragraph", "Para.\n.. note::\n", 2, itemParagraph},
+}
+
+func TestLexLineClassification(t *testing.T) {
+	for _, test := range lineClassTests {
+		var got *item
+		for _, i := range lexAll(test.input) {
+			if i.Line == test.line && i.Type != itemSpace {
+				got = &i
+				break
+			}
+		}
+		if got == nil {
+			t.Errorf("%s: no item on line %d", test.name, test.line)
+		} else if got.Type != test.expect {
+			t.Errorf("%s: line %d is %s, Expect: %s", test.name,
+				test.line, got.Type, test.expect)
+		}
+	}
+}
+
+// bulletLexTests are the items lexed from bullet list items. Only the type,
+// text, line, and start position of each item are compared. Positions are
+// byte offsets, so the body of an item with a multibyte bullet begins past
+// the width of the bullet.
+var bulletLexTests = []struct {
+	name   string
+	input  string
+	expect []item
+}{
+	{"bullet at EOF", "*", []item{
+		{Type: itemBullet, Text: "*", Line: 1, StartPosition: 1},
+		{Type: itemEOF, Line: 1, StartPosition: 2},
+	}},
+	{"bullet without space", "*item", []item{
+		{Type: itemParagraph, Text: "*item", Line: 1, StartPosition: 1},
+		{Type: itemEOF, Line: 1, StartPosition: 6},
+	}},
+	{"bullet and tab", "-\titem", []item{
+		{Type: itemParagraph, Text: "-\titem", Line: 1, StartPosition: 1},
+		{Type: itemEOF, Line: 1, StartPosition: 7},
+	}},
+	{"bullet runes", "*\n+\n-\n\u2022\n\u2023\n\u2043", []item{
+		{Type: itemBullet, Text: "*", Line: 1, StartPosition: 1},
+		{Type: itemBullet, Text: "+", Line: 2, StartPosition: 1},
+		{Type: itemBullet, Text: "-", Line: 3, StartPosition: 1},
+		{Type: itemBullet, Text: "\u2022", Line: 4, StartPosition: 1},
+		{Type: itemBullet, Text: "\u2023", Line: 5, StartPosition: 1},
+		{Type: itemBullet, Text: "\u2043", Line: 6, StartPosition: 1},
+		{Type: itemEOF, Line: 6, StartPosition: 4},
+	}},
+	{"item body", "\u2022 item", []item{
+		{Type: itemBullet, Text: "\u2022", Line: 1, StartPosition: 1},
+		{Type: itemSpace, Text: " ", Line: 1, StartPosition: 4},
+		{Type: itemParagraph, Text: "item", Line: 1, StartPosition: 5},
+		{Type: itemEOF, Line: 1, StartPosition: 9},
+	}},
+	{"nested item", "- a\n\n  + b", []item{
+		{Type: itemBullet, Text: "-", Line: 1, StartPosition: 1},
+		{Type: itemSpace, Text: " ", Line: 1, StartPosition: 2},
+		{Type: itemParagraph, Text: "a", Line: 1, StartPosition: 3},
+		{Type: itemBlankLine, Text: "\n", Line: 2, StartPosition: 1},
+		{Type: itemSpace, Text: "  ", Line: 3, StartPosition: 1},
+		{Type: itemBullet, Text: "+", Line: 3, StartPosition: 3},
+		{Type: itemSpace, Text: " ", Line: 3, StartPosition: 4},
+		{Type: itemParagraph, Text: "b", Line: 3, StartPosition: 5},
+		{Type: itemEOF, Line: 3, StartPosition: 6},
+	}},
+}
+
+func TestLexBulletItems(t *testing.T) {
+	for _, test := range bulletLexTests {
+		var got []item
+		for _, i := range lexAll(test.input) {
+			got = append(got, item{Type: i.Type, Text: i.Text, Line: i.Line,
+				StartPosition: i.StartPosition})
+		}
+		if !reflect.DeepEqual(got, test.expect) {
+			t.Errorf("%s:\nGot:    %v\nExpect: %v", test.name, got,
+				test.expect)
+		}
+	}
+}
+
+// enumeratorTests are the enumerators scanned at the start of a line. next
+// is the enumerator expected to follow, or "" if the line does not begin with
+// an enumerator.
+var enumeratorTests = []struct {
+	input string
+	seq   itemElement
+	next  string
+}{
+	{"1. text", itemEnumListArabic, "2."},
+	{"09) text", itemEnumListArabic, "10)"},
+	{"(99) text", itemEnumListArabic, "(100)"},
+	{"a. text", itemEnumListAlpha, "b."},
+	{"(Y)", itemEnumListAlpha, "(Z)"},
+	{"z)", itemEnumListAlpha, ""},
+	{"i. text", itemEnumListRoman, "ii."},
+	{"(I) text", itemEnumListRoman, "(II)"},
+	{"v. text", itemEnumListAlpha, "w."},
+	{"iii. text", itemEnumListRoman, "iv."},
+	{"XLIX) text", itemEnumListRoman, "L)"},
+	{"#. text", itemEnumListAuto, "#."},
+	{"1.text", itemEOF, ""},
+	{"1 text", itemEOF, ""},
+	{"(1. text", itemEOF, ""},
+	{"1.5 text", itemEOF, ""},
+	{"ab. text", itemEOF, ""},
+	{"IIII. text", itemEOF, ""},
+	{"Ii. text", itemEOF, ""},
+	{"MMMMM. text", itemEOF, ""},
+}
+
+func TestLexScanEnumerator(t *testing.T) {
+	for _, test := range enumeratorTests {
+		e, ok := scanEnumerator(test.input)
+		if !ok {
+			if test.next != "" || test.seq != itemEOF {
+				t.Errorf("%q: no enumerator found", test.input)
+			}
+			continue
+		}
+		if e.seq != test.seq || e.next() != test.next {
+			t.Errorf("%q: Got: %s followed by %q, Expect: %s followed by "+
+				"%q", test.input, e.seq, e.next(), test.seq, test.next)
+		}
+	}
+}
+
+// numberingHash returns a hash of the names and their numbers.
+func numberingHash(names []string) string {
+	h := sha1.New()
+	for num, name := range names {
+		fmt.Fprintf(h, "%s=%d\n", name, num)
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+// The hash of the names and numbers of every itemElement. Appending a new
+// itemElement changes the hash, it must then be updated here.
+const elementsHash = "b9ce3400e963fc66b108c89e690d3247a2fcde4e"
+
+func TestItemElementNumbering(t *testing.T) {
+	if h := numberingHash(elements[:]); h != elementsHash {
+		t.Errorf("The numbering of the itemElements has changed!\n\t"+
+			"Fixtures and other encoded items depend on these numbers. "+
+			"New itemElements\n\tmust be appended to the end of the "+
+			"itemElement constants and elements,\n\tthen elementsHash "+
+			"is updated.\n\tGot hash: %s, Expect: %s", h, elementsHash)
+	}
+}
+
+func TestItemElementJSON(t *testing.T) {
+	data, err := json.Marshal(item{Type: itemParagraph})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"type":"itemParagraph"`) {
+		t.Errorf("Expected the itemElement name in %s", data)
+	}
+	for _, in := range []string{`"itemParagraph"`, `4`} {
+		var e itemElement
+		if err := json.Unmarshal([]byte(in), &e); err != nil ||
+			e != itemParagraph {
+			t.Errorf("%s: Got: %s (%v), Expect: itemParagraph", in, e,
+				err)
+		}
+	}
+	for _, in := range []string{`"itemUnknown"`, `99`, `-1`, `true`} {
+		var e itemElement
+		if err := json.Unmarshal([]byte(in), &e); err == nil {
+			t.Errorf("%s: Expected an error", in)
+		}
+	}
+}
+
+func TestItemLogString(t *testing.T) {
+	i := &item{ID: 3, Type: itemParagraph, Text: "Short.", Line: 2,
+		StartPosition: 1}
+	exp := `itemParagraph ID=3 line 2 position 1: "Short."`
+	if s := i.logString(); s != exp {
+		t.Errorf("Got: %s\n\t Expect: %s", s, exp)
+	}
+	i.Text = strings.Repeat("é", maxLogExcerpt+10)
+	exp = fmt.Sprintf(`itemParagraph ID=3 line 2 position 1: %q...(+20 bytes)`,
+		strings.Repeat("é", maxLogExcerpt))
+	if s := i.logString(); s != exp {
+		t.Errorf("Got: %s\n\t Expect: %s", s, exp)
+	}
+}
+
+// largeParagraph is a single line paragraph of about one megabyte.
+var largeParagraph = strings.Repeat("Lorem ipsum dolor sit amet. ", 1<<15)
+
+func TestLexLogLargeItem(t *testing.T) {
+	var buf bytes.Buffer
+	defer log.SetStreams(log.Streams()...)
+	defer log.SetLevel(log.Level())
+	log.SetStreams(&buf)
+	log.SetLevel(log.LEVEL_INFO)
+	Parse("large", largeParagraph)
+	log.SetLevel(log.LEVEL_CRITICAL)
+
+	if !strings.Contains(buf.String(), "itemParagraph ID=1") {
+		t.Fatalf("The paragraph item was not logged:\n%s", buf.String())
+	}
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if len(line) > 512 {
+			t.Errorf("Log line of %d bytes: %.80s...", len(line), line)
+		}
+	}
+}
+
+func BenchmarkLexLargeParagraph(b *testing.B) {
+	b.SetBytes(int64(len(largeParagraph)))
+	for i := 0; i < b.N; i++ {
+		for l := lex("large", largeParagraph); l.nextItem().Type != itemEOF; {
+		}
+	}
+}
@@ -0,0 +1,99 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import "testing"
+
+func TestLexTransitionAtStartBad0000(t *testing.T) {
+	// A transition at the start of the document.
+	testPath := testPathFromName("00.00-transition-at-start")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexTransitionAtEndBad0001(t *testing.T) {
+	// A transition at the end of the document.
+	testPath := testPathFromName("00.01-transition-at-end")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexTransitionAdjacentBad0002(t *testing.T) {
+	// Two transitions with no element between them.
+	testPath := testPathFromName("00.02-adjacent-transitions")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexTransitionBeginsSectionBad0003(t *testing.T) {
+	// A transition directly below a section title.
+	testPath := testPathFromName("00.03-transition-begins-section")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexTransitionAfterParagraphBad0004(t *testing.T) {
+	// An adornment line directly below a paragraph is a short underline,
+	// not a transition.
+	testPath := testPathFromName("00.04-transition-after-paragraph")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexTransitionAfterParagraphInListBad0005(t *testing.T) {
+	// An adornment line directly below a paragraph in a list item is an
+	// unexpected section title.
+	testPath := testPathFromName("00.05-transition-after-paragraph-in-list")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexTransitionBelowTitleBad0006(t *testing.T) {
+	// An adornment line directly below the underline of a title is a
+	// transition that begins the section.
+	testPath := testPathFromName("00.06-transition-below-title")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexTransitionBelowTitleAtEndBad0007(t *testing.T) {
+	// A transition directly below the underline of a title at the end of
+	// the input.
+	testPath := testPathFromName("00.07-transition-below-title-at-end")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexTransitionGood0000(t *testing.T) {
+	// A transition between two paragraphs.
+	testPath := testPathFromName("00.00-transition-between-paragraphs")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexTransitionInSectionGood0001(t *testing.T) {
+	// A transition of asterisks between two paragraphs of a section.
+	testPath := testPathFromName("00.01-transition-in-section")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexTransitionBeforeCommentGood0002(t *testing.T) {
+	// A transition followed by a comment does not end the document.
+	testPath := testPathFromName("00.02-transition-before-comment")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
@@ -0,0 +1,80 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import (
+	"reflect"
+	"time"
+)
+
+// Metrics contains statistics collected while parsing a document. Durations
+// are measured at the boundaries of each phase, so collecting metrics adds no
+// timing calls to the lexer or the parser loop.
+type Metrics struct {
+	InputSize int // The size of the input in bytes
+
+	// Normalize is the time spent converting the input to Unicode
+	// Normalization Form C.
+	Normalize time.Duration
+
+	// Scan is the time spent scanning the indentation of the input lines
+	// for non-ASCII whitespace.
+	Scan time.Duration
+
+	// Parse is the time spent lexing and parsing the input. The parser
+	// pulls each item from the lexer as it needs it, so lexing is
+	// interleaved with parsing and is not measured separately.
+	Parse time.Duration
+
+	// Total is the time spent in Parse.
+	Total time.Duration
+
+	Items    int // The number of items emitted by the lexer
+	Nodes    int // The number of nodes in the parse tree
+	Messages int // The number of system messages generated by the parser
+}
+
+// WithMetrics collects parse statistics into m. m is overwritten on each
+// call to Parse.
+func WithMetrics(m *Metrics) ParseOption {
+	return func(t *Tree) { t.metrics = m }
+}
+
+// collectMetrics counts the items, nodes, and messages of the parsed tree.
+func (t *Tree) collectMetrics() {
+	if t.lex != nil {
+		// The lexer is done once the parser has received itemEOF.
+		t.metrics.Items = t.lex.id
+	}
+	t.metrics.Nodes = t.sentNodes + countNodes(t.Nodes)
+	t.metrics.Messages = len(t.Messages)
+}
+
+// countNodes returns the number of nodes in nl, including the children of the
+// nodes and nodes that are fields of other nodes, such as section titles.
+func countNodes(nl NodeList) (count int) {
+	for _, n := range nl {
+		count += countNode(n)
+	}
+	return
+}
+
+func countNode(n Node) int {
+	v := reflect.Indirect(reflect.ValueOf(n))
+	count := 1
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Field(i)
+		if v.Type().Field(i).PkgPath != "" {
+			continue
+		}
+		if nl, ok := f.Interface().(NodeList); ok {
+			count += countNodes(nl)
+		} else if f.Kind() == reflect.Ptr && !f.IsNil() &&
+			f.Type().Implements(nodeInterface) {
+			count += countNode(f.Interface().(Node))
+		}
+	}
+	return count
+}
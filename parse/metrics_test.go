@@ -0,0 +1,80 @@
+package parse
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+// countJSONNodes counts the objects with a "type" field in the JSON encoding
+// of a NodeList.
+func countJSONNodes(v interface{}) (count int) {
+	switch v := v.(type) {
+	case []interface{}:
+		for _, e := range v {
+			count += countJSONNodes(e)
+		}
+	case map[string]interface{}:
+		if _, ok := v["type"]; ok {
+			count++
+		}
+		for _, e := range v {
+			count += countJSONNodes(e)
+		}
+	}
+	return
+}
+
+func TestParseWithMetrics(t *testing.T) {
+	text := "Title\n=====\n\nParagraph.\n\n    A quote.\n\n" +
+		"Short Title\n===\n\n- Bullet\n"
+	var m Metrics
+	tree, errors := Parse("metrics", text, WithMetrics(&m))
+
+	if m.InputSize != len(text) {
+		t.Errorf("InputSize: Got: %d, Expect: %d", m.InputSize, len(text))
+	}
+	items := 0
+	for l := lex("metrics", text); ; {
+		items++
+		if l.nextItem().Type == itemEOF {
+			break
+		}
+	}
+	if m.Items != items {
+		t.Errorf("Items: Got: %d, Expect: %d", m.Items, items)
+	}
+	data, err := json.Marshal(tree.Nodes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var nodes interface{}
+	if err := json.Unmarshal(data, &nodes); err != nil {
+		t.Fatal(err)
+	}
+	if exp := countJSONNodes(nodes); m.Nodes != exp {
+		t.Errorf("Nodes: Got: %d, Expect: %d", m.Nodes, exp)
+	}
+	if len(errors) == 0 || m.Messages != len(errors) {
+		t.Errorf("Messages: Got: %d, Expect: %d", m.Messages, len(errors))
+	}
+
+	// The phases do not overlap, so their sum cannot exceed the total.
+	// Only the code between the phases is not measured.
+	sum := m.Normalize + m.Scan + m.Parse
+	if m.Parse == 0 || sum > m.Total || m.Total-sum > 50*time.Millisecond {
+		t.Errorf("Phase durations %s (normalize %s, scan %s, parse %s) "+
+			"do not add up to total %s", sum, m.Normalize, m.Scan,
+			m.Parse, m.Total)
+	}
+}
+
+func TestParseWithMetricsReset(t *testing.T) {
+	var m Metrics
+	Parse("metrics", strings.Repeat("Paragraph.\n\n", 10), WithMetrics(&m))
+	Parse("metrics", "Paragraph.\n", WithMetrics(&m))
+	if m.Nodes != 1 || m.Messages != 0 {
+		t.Errorf("Expected the metrics of the last parse, got: %+v", m)
+	}
+}
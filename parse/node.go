@@ -4,7 +4,15 @@
 
 package parse
 
-// NodeType identifies the type of a parse tree node.
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// NodeType identifies the type of a parse tree node. New types must be
+// appended to the end of the list, the numbers of the existing types must not
+// change.
 type NodeType int
 
 const (
@@ -46,6 +54,54 @@ const (
 	NodeDefinitionListItem
 	NodeDefinitionTerm
 	NodeDefinition
+
+	// NodeAttribution is the attribution ending a blockquote element.
+	NodeAttribution
+
+	// NodeEnumListItem is an item of an enumerated list
+	NodeEnumListItem
+
+	// NodeClassifier is a classifier of a definition list term
+	NodeClassifier
+
+	// NodeFieldList is a field list element
+	NodeFieldList
+
+	// NodeField is a field of a field list
+	NodeField
+
+	// NodeFieldName is the name of a field
+	NodeFieldName
+
+	// NodeFieldBody is the body of a field
+	NodeFieldBody
+
+	// NodeDocinfo contains the bibliographic fields of a document
+	NodeDocinfo
+
+	// NodeBibliographicField is a registered field of the docinfo
+	NodeBibliographicField
+
+	// NodeOptionList is an option list element
+	NodeOptionList
+
+	// NodeOptionListItem is an item of an option list
+	NodeOptionListItem
+
+	// NodeOption is an option of an option list item
+	NodeOption
+
+	// NodeOptionArgument is the argument of an option
+	NodeOptionArgument
+
+	// NodeDescription is the description of an option list item
+	NodeDescription
+
+	// NodeDoctestBlock is a doctest block element
+	NodeDoctestBlock
+
+	// NodeCodeBlock is a code directive
+	NodeCodeBlock
 )
 
 var nodeTypes = [...]string{
@@ -65,6 +121,22 @@ var nodeTypes = [...]string{
 	"NodeDefinitionListItem",
 	"NodeDefinitionTerm",
 	"NodeDefinition",
+	"NodeAttribution",
+	"NodeEnumListItem",
+	"NodeClassifier",
+	"NodeFieldList",
+	"NodeField",
+	"NodeFieldName",
+	"NodeFieldBody",
+	"NodeDocinfo",
+	"NodeBibliographicField",
+	"NodeOptionList",
+	"NodeOptionListItem",
+	"NodeOption",
+	"NodeOptionArgument",
+	"NodeDescription",
+	"NodeDoctestBlock",
+	"NodeCodeBlock",
 }
 
 // Type returns the type of a node element.
@@ -76,6 +148,23 @@ func (n NodeType) String() string {
 	return nodeTypes[n]
 }
 
+// MarshalJSON implements json.Marshaler. The type is encoded by its name, such
+// as "NodeParagraph".
+func (n NodeType) MarshalJSON() ([]byte, error) {
+	return json.Marshal(n.String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler. The type can be given by its name
+// or by its number.
+func (n *NodeType) UnmarshalJSON(data []byte) error {
+	num, err := unmarshalName(data, nodeTypes[:])
+	if err != nil {
+		return fmt.Errorf("NodeType: %s", err)
+	}
+	*n = NodeType(num)
+	return nil
+}
+
 // Node is the interface used to implement parser nodes.
 type Node interface {
 	IDNumber() ID
@@ -113,6 +202,23 @@ func (e EnumListType) String() string {
 	return enumListTypes[e]
 }
 
+// MarshalJSON implements json.Marshaler. The type is encoded by its name, such
+// as "enumListArabic".
+func (e EnumListType) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler. The type can be given by its name
+// or by its number.
+func (e *EnumListType) UnmarshalJSON(data []byte) error {
+	num, err := unmarshalName(data, enumListTypes[:])
+	if err != nil {
+		return fmt.Errorf("EnumListType: %s", err)
+	}
+	*e = EnumListType(num)
+	return nil
+}
+
 type EnumAffixType int
 
 const (
@@ -131,6 +237,23 @@ func (a EnumAffixType) String() string {
 	return enumAffixesTypes[a]
 }
 
+// MarshalJSON implements json.Marshaler. The type is encoded by its name, such
+// as "enumAffixPeriod".
+func (a EnumAffixType) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler. The type can be given by its name
+// or by its number.
+func (a *EnumAffixType) UnmarshalJSON(data []byte) error {
+	num, err := unmarshalName(data, enumAffixesTypes[:])
+	if err != nil {
+		return fmt.Errorf("EnumAffixType: %s", err)
+	}
+	*a = EnumAffixType(num)
+	return nil
+}
+
 // SectionNode is a a single section node. It contains overline, title, and
 // underline nodes. NodeList contains nodes that are children of the section.
 type SectionNode struct {
@@ -294,6 +417,34 @@ func (b BlockQuoteNode) NodeType() NodeType {
 	return b.Type
 }
 
+// AttributionNode is the attribution of a blockquote. Text does not include the
+// attribution marker ("--", "---", or an em dash).
+type AttributionNode struct {
+	ID            `json:"id"`
+	Type          NodeType `json:"type"`
+	Text          string   `json:"text"`
+	Length        int      `json:"length"`
+	Line          `json:"line"`
+	StartPosition `json:"startPosition"`
+}
+
+func newAttribution(i *item, id *int) *AttributionNode {
+	*id++
+	return &AttributionNode{
+		ID:            ID(*id),
+		Type:          NodeAttribution,
+		Text:          i.Text,
+		Length:        i.Length,
+		Line:          i.Line,
+		StartPosition: i.StartPosition,
+	}
+}
+
+// NodeType returns the Node type of the AttributionNode.
+func (a AttributionNode) NodeType() NodeType {
+	return a.Type
+}
+
 // SystemMessageNode are messages generated by the parser. System messages are
 // leveled by severity and can be one of either Warning, Error, Info, and
 // Severe.
@@ -307,7 +458,7 @@ type SystemMessageNode struct {
 
 	// Severity is the level of importance of the message. It can be one of
 	// either info, warning, error, and severe.
-	Severity systemMessageLevel `json:"severity"`
+	Severity SystemMessageLevel `json:"severity"`
 
 	// NodeList contains children Nodes of the systemMessage. Typically
 	// containing the first list item as a NodeParagraph which contains the
@@ -359,6 +510,35 @@ func (l LiteralBlockNode) NodeType() NodeType {
 	return l.Type
 }
 
+// DoctestBlockNode is a parsed doctest block element, an interactive Python
+// session beginning with ">>>". The text contains the lines of the block as
+// written, including the output of the session.
+type DoctestBlockNode struct {
+	ID            `json:"id"`
+	Type          NodeType `json:"type"`
+	Text          string   `json:"text"`
+	Length        int      `json:"length"`
+	StartPosition `json:"startPosition"`
+	Line          `json:"line"`
+}
+
+func newDoctestBlock(i *item, id *int) *DoctestBlockNode {
+	*id++
+	return &DoctestBlockNode{
+		ID:            ID(*id),
+		Type:          NodeDoctestBlock,
+		Text:          i.Text,
+		Length:        i.Length,
+		StartPosition: i.StartPosition,
+		Line:          i.Line,
+	}
+}
+
+// NodeType returns the Node type of DoctestBlockNode.
+func (d DoctestBlockNode) NodeType() NodeType {
+	return d.Type
+}
+
 // TransitionNode is a parsed transition element. Transition elements are very
 // similar to AdornmentNodes.
 type TransitionNode struct {
@@ -467,25 +647,42 @@ type EnumListNode struct {
 	NodeList `json:"nodeList"`
 }
 
-// newEnumListNode initializes a new EnumListNode.
-func newEnumListNode(enumList *item, affix *item, id *int) *EnumListNode {
-	*id++
-	var enType EnumListType
+// enumListFormat returns the sequence and the affix type of the enumerator
+// enumList, where affix is the first affix of the enumerator.
+func enumListFormat(enumList *item, affix *item) (enType EnumListType,
+	afType EnumAffixType) {
+	upper := enumList.Text != strings.ToLower(enumList.Text)
 	switch enumList.Type {
 	case itemEnumListArabic:
 		enType = enumListArabic
+	case itemEnumListAlpha:
+		enType = enumListLowerAlpha
+		if upper {
+			enType = enumListUpperAlpha
+		}
+	case itemEnumListRoman:
+		enType = enumListLowerRoman
+		if upper {
+			enType = enumListUpperRoman
+		}
+	case itemEnumListAuto:
+		enType = enumListAuto
 	}
-
-	var afType EnumAffixType
 	switch affix.Text {
 	case ".":
 		afType = enumAffixPeriod
-		// case "(":
-		// afType = enumAffixParenthesisSurround
-		// case ")":
-		// afType = enumAffixParenthesisRight
+	case "(":
+		afType = enumAffixParenthesisSurround
+	case ")":
+		afType = enumAffixParenthesisRight
 	}
+	return
+}
 
+// newEnumListNode initializes a new EnumListNode.
+func newEnumListNode(enumList *item, affix *item, id *int) *EnumListNode {
+	*id++
+	enType, afType := enumListFormat(enumList, affix)
 	return &EnumListNode{
 		ID:       ID(*id),
 		Type:     NodeEnumList,
@@ -499,6 +696,32 @@ func (e EnumListNode) NodeType() NodeType {
 	return e.Type
 }
 
+// EnumListItemNode is an item of an enumerated list. Ordinal is the number of
+// the item in its list, which is also computed for auto enumerators ("#").
+type EnumListItemNode struct {
+	ID       `json:"id"`
+	Type     NodeType `json:"type"`
+	Line     `json:"line"`
+	Ordinal  int `json:"ordinal"`
+	NodeList `json:"nodeList"`
+}
+
+// newEnumListItemNode initializes a new EnumListItemNode.
+func newEnumListItemNode(i *item, ordinal int, id *int) *EnumListItemNode {
+	*id++
+	return &EnumListItemNode{
+		ID:      ID(*id),
+		Type:    NodeEnumListItem,
+		Line:    i.Line,
+		Ordinal: ordinal,
+	}
+}
+
+// NodeType returns the Node type of the EnumListItemNode.
+func (e EnumListItemNode) NodeType() NodeType {
+	return e.Type
+}
+
 type DefinitionListNode struct {
 	ID       `json:"id"`
 	Type     NodeType `json:"type"`
@@ -520,14 +743,16 @@ func (d DefinitionListNode) NodeType() NodeType {
 }
 
 type DefinitionListItemNode struct {
-	ID         `json:"id"`
-	Type       NodeType `json:"type"`
-	Line       `json:"line"`
-	Term       *DefinitionTermNode `json:"term"`
-	Definition *DefinitionNode     `json:"definition"`
+	ID          `json:"id"`
+	Type        NodeType `json:"type"`
+	Line        `json:"line"`
+	Term        *DefinitionTermNode `json:"term"`
+	Classifiers NodeList            `json:"classifiers"`
+	Definition  *DefinitionNode     `json:"definition"`
 }
 
-func newDefinitionListItem(defTerm *item, def *item, id *int) *DefinitionListItemNode {
+func newDefinitionListItem(defTerm *item, classifiers []*item, def *item,
+	id *int) *DefinitionListItemNode {
 	*id++
 	n := &DefinitionListItemNode{
 		ID:   ID(*id),
@@ -543,6 +768,9 @@ func newDefinitionListItem(defTerm *item, def *item, id *int) *DefinitionListIte
 		StartPosition: defTerm.StartPosition,
 		Line:          defTerm.Line,
 	}
+	for _, c := range classifiers {
+		n.Classifiers.append(newClassifier(c, id))
+	}
 	*id++
 	nd := &DefinitionNode{
 		ID:   ID(*id),
@@ -571,6 +799,35 @@ func (d DefinitionTermNode) NodeType() NodeType {
 	return d.Type
 }
 
+// ClassifierNode is a classifier following a definition list term, such as
+// "cls" in "term : cls".
+type ClassifierNode struct {
+	ID            `json:"id"`
+	Type          NodeType `json:"type"`
+	Text          string   `json:"text"`
+	Length        int      `json:"length"`
+	StartPosition `json:"startPosition"`
+	Line          `json:"line"`
+}
+
+// newClassifier initializes a new ClassifierNode.
+func newClassifier(i *item, id *int) *ClassifierNode {
+	*id++
+	return &ClassifierNode{
+		ID:            ID(*id),
+		Type:          NodeClassifier,
+		Text:          i.Text,
+		Length:        i.Length,
+		StartPosition: i.StartPosition,
+		Line:          i.Line,
+	}
+}
+
+// NodeType returns the Node type of the ClassifierNode.
+func (c ClassifierNode) NodeType() NodeType {
+	return c.Type
+}
+
 type DefinitionNode struct {
 	ID       `json:"id"`
 	Type     NodeType `json:"type"`
@@ -581,3 +838,284 @@ type DefinitionNode struct {
 func (d DefinitionNode) NodeType() NodeType {
 	return d.Type
 }
+
+// FieldListNode is a field list. NodeList contains the FieldNodes of the list.
+type FieldListNode struct {
+	ID       `json:"id"`
+	Type     NodeType `json:"type"`
+	Line     `json:"line"`
+	NodeList `json:"nodeList"`
+}
+
+// newFieldList initializes a new FieldListNode.
+func newFieldList(i *item, id *int) *FieldListNode {
+	*id++
+	return &FieldListNode{
+		ID:   ID(*id),
+		Type: NodeFieldList,
+		Line: i.Line,
+	}
+}
+
+// NodeType returns the Node type of the FieldListNode.
+func (f FieldListNode) NodeType() NodeType {
+	return f.Type
+}
+
+// FieldNode is a field of a field list, such as ":Author: J. Smith".
+type FieldNode struct {
+	ID   `json:"id"`
+	Type NodeType `json:"type"`
+	Line `json:"line"`
+	Name *FieldNameNode `json:"name"`
+	Body *FieldBodyNode `json:"body"`
+}
+
+// newField initializes a new FieldNode with the name i and an empty body.
+func newField(i *item, id *int) *FieldNode {
+	*id++
+	n := &FieldNode{
+		ID:   ID(*id),
+		Type: NodeField,
+		Line: i.Line,
+	}
+	*id++
+	n.Name = &FieldNameNode{
+		ID:            ID(*id),
+		Type:          NodeFieldName,
+		Text:          i.Text,
+		Length:        i.Length,
+		StartPosition: i.StartPosition,
+		Line:          i.Line,
+	}
+	*id++
+	n.Body = &FieldBodyNode{
+		ID:   ID(*id),
+		Type: NodeFieldBody,
+		Line: i.Line,
+	}
+	return n
+}
+
+// NodeType returns the Node type of the FieldNode.
+func (f FieldNode) NodeType() NodeType {
+	return f.Type
+}
+
+// FieldNameNode is the name of a field. Text is the name as written, so it
+// includes the backslashes escaping colons.
+type FieldNameNode struct {
+	ID            `json:"id"`
+	Type          NodeType `json:"type"`
+	Text          string   `json:"text"`
+	Length        int      `json:"length"`
+	StartPosition `json:"startPosition"`
+	Line          `json:"line"`
+}
+
+// NodeType returns the Node type of the FieldNameNode.
+func (f FieldNameNode) NodeType() NodeType {
+	return f.Type
+}
+
+// FieldBodyNode is the body of a field. NodeList contains the paragraphs of
+// the body, it is empty if the field has no body.
+type FieldBodyNode struct {
+	ID       `json:"id"`
+	Type     NodeType `json:"type"`
+	Line     `json:"line"`
+	NodeList `json:"nodeList"`
+}
+
+// NodeType returns the Node type of the FieldBodyNode.
+func (f FieldBodyNode) NodeType() NodeType {
+	return f.Type
+}
+
+// DocinfoNode contains the fields of the field list at the start of a
+// document, which are converted by WithDocinfo. NodeList contains
+// BibliographicFieldNodes and FieldNodes.
+type DocinfoNode struct {
+	ID       `json:"id"`
+	Type     NodeType `json:"type"`
+	Line     `json:"line"`
+	NodeList `json:"nodeList"`
+}
+
+// NodeType returns the Node type of the DocinfoNode.
+func (d DocinfoNode) NodeType() NodeType {
+	return d.Type
+}
+
+// BibliographicFieldNode is a field of the docinfo with a registered name,
+// such as ":Author: J. Smith". Field is the registered name in lower case,
+// such as "author", and Name is the name as written.
+type BibliographicFieldNode struct {
+	ID    `json:"id"`
+	Type  NodeType `json:"type"`
+	Line  `json:"line"`
+	Field string         `json:"field"`
+	Name  *FieldNameNode `json:"name"`
+	Body  *FieldBodyNode `json:"body"`
+}
+
+// NodeType returns the Node type of the BibliographicFieldNode.
+func (b BibliographicFieldNode) NodeType() NodeType {
+	return b.Type
+}
+
+// OptionListNode is an option list. NodeList contains the OptionListItemNodes
+// of the list.
+type OptionListNode struct {
+	ID       `json:"id"`
+	Type     NodeType `json:"type"`
+	Line     `json:"line"`
+	NodeList `json:"nodeList"`
+}
+
+// newOptionList initializes a new OptionListNode.
+func newOptionList(i *item, id *int) *OptionListNode {
+	*id++
+	return &OptionListNode{
+		ID:   ID(*id),
+		Type: NodeOptionList,
+		Line: i.Line,
+	}
+}
+
+// NodeType returns the Node type of the OptionListNode.
+func (o OptionListNode) NodeType() NodeType {
+	return o.Type
+}
+
+// OptionListItemNode is an item of an option list, such as "-a, --all  Show
+// all.". NodeList contains the OptionNodes of the option group.
+type OptionListItemNode struct {
+	ID          `json:"id"`
+	Type        NodeType `json:"type"`
+	Line        `json:"line"`
+	NodeList    `json:"nodeList"`
+	Description *DescriptionNode `json:"description"`
+}
+
+// newOptionListItem initializes a new OptionListItemNode with no options and
+// an empty description.
+func newOptionListItem(i *item, id *int) *OptionListItemNode {
+	*id++
+	n := &OptionListItemNode{
+		ID:   ID(*id),
+		Type: NodeOptionListItem,
+		Line: i.Line,
+	}
+	*id++
+	n.Description = &DescriptionNode{
+		ID:   ID(*id),
+		Type: NodeDescription,
+		Line: i.Line,
+	}
+	return n
+}
+
+// NodeType returns the Node type of the OptionListItemNode.
+func (o OptionListItemNode) NodeType() NodeType {
+	return o.Type
+}
+
+// OptionNode is an option of an option group. Text is the option string, such
+// as "--file" in "--file=FILE", and Argument is nil if the option has no
+// argument.
+type OptionNode struct {
+	ID            `json:"id"`
+	Type          NodeType `json:"type"`
+	Text          string   `json:"text"`
+	Length        int      `json:"length"`
+	StartPosition `json:"startPosition"`
+	Line          `json:"line"`
+	Argument      *OptionArgumentNode `json:"argument"`
+}
+
+// newOption initializes a new OptionNode with the option string i.
+func newOption(i *item, id *int) *OptionNode {
+	*id++
+	return &OptionNode{
+		ID:            ID(*id),
+		Type:          NodeOption,
+		Text:          i.Text,
+		Length:        i.Length,
+		StartPosition: i.StartPosition,
+		Line:          i.Line,
+	}
+}
+
+// NodeType returns the Node type of the OptionNode.
+func (o OptionNode) NodeType() NodeType {
+	return o.Type
+}
+
+// OptionArgumentNode is the argument of an option, such as "FILE" in
+// "--file=FILE". Delimiter is the text separating the argument from the
+// option string, which is "=", a space, or empty for short options such as
+// "-fFILE".
+type OptionArgumentNode struct {
+	ID            `json:"id"`
+	Type          NodeType `json:"type"`
+	Text          string   `json:"text"`
+	Length        int      `json:"length"`
+	StartPosition `json:"startPosition"`
+	Line          `json:"line"`
+	Delimiter     string `json:"delimiter"`
+}
+
+// NodeType returns the Node type of the OptionArgumentNode.
+func (o OptionArgumentNode) NodeType() NodeType {
+	return o.Type
+}
+
+// DescriptionNode is the description of an option list item. NodeList
+// contains the paragraphs of the description.
+type DescriptionNode struct {
+	ID       `json:"id"`
+	Type     NodeType `json:"type"`
+	Line     `json:"line"`
+	NodeList `json:"nodeList"`
+}
+
+// NodeType returns the Node type of the DescriptionNode.
+func (d DescriptionNode) NodeType() NodeType {
+	return d.Type
+}
+
+// CodeBlockNode is a parsed "code" directive, or "code-block" directive of
+// Sphinx. Language is the language given as the argument of the directive,
+// such as "python", empty if none is given. NumberLines is the number of the
+// first line if the lines are numbered, or 0. The text contains the content of
+// the directive as written, without its indentation, including blank lines and
+// tabs.
+type CodeBlockNode struct {
+	ID            `json:"id"`
+	Type          NodeType `json:"type"`
+	Language      string   `json:"language"`
+	NumberLines   int      `json:"numberLines"`
+	Text          string   `json:"text"`
+	Length        int      `json:"length"`
+	StartPosition `json:"startPosition"`
+	Line          `json:"line"`
+}
+
+func newCodeBlock(i *item, language string, id *int) *CodeBlockNode {
+	*id++
+	return &CodeBlockNode{
+		ID:            ID(*id),
+		Type:          NodeCodeBlock,
+		Language:      language,
+		Text:          i.Text,
+		Length:        i.Length,
+		StartPosition: i.StartPosition,
+		Line:          i.Line,
+	}
+}
+
+// NodeType returns the Node type of CodeBlockNode.
+func (c CodeBlockNode) NodeType() NodeType {
+	return c.Type
+}
@@ -1,6 +1,8 @@
 package parse
 
 import (
+	"encoding/json"
+	"strings"
 	"testing"
 )
 
@@ -39,6 +41,13 @@ func TestTitleNodeType(t *testing.T) {
 	}
 }
 
+func TestAttributionNodeType(t *testing.T) {
+	n := &AttributionNode{Type: NodeAttribution}
+	if n.NodeType() != NodeAttribution {
+		t.Error("n.Type != NodeAttribution")
+	}
+}
+
 func TestCommentNodeType(t *testing.T) {
 	n := &CommentNode{Type: NodeComment}
 	if n.NodeType() != NodeComment {
@@ -80,3 +89,51 @@ func TestBulletListType(t *testing.T) {
 		t.Error("n.Type != NodeBulletList")
 	}
 }
+
+// The hash of the names and numbers of every NodeType. Appending a new
+// NodeType changes the hash, it must then be updated here.
+const nodeTypesHash = "68699520f1845bf0773c5d1f63dbb0b6744101be"
+
+func TestNodeTypeNumbering(t *testing.T) {
+	if h := numberingHash(nodeTypes[:]); h != nodeTypesHash {
+		t.Errorf("The numbering of the NodeTypes has changed!\n\t"+
+			"New NodeTypes must be appended to the end of the NodeType "+
+			"constants and nodeTypes,\n\tthen nodeTypesHash is "+
+			"updated.\n\tGot hash: %s, Expect: %s", h, nodeTypesHash)
+	}
+}
+
+func TestNodeTypeJSON(t *testing.T) {
+	data, err := json.Marshal(&ParagraphNode{Type: NodeParagraph})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var n struct {
+		Type NodeType `json:"type"`
+	}
+	if err := json.Unmarshal(data, &n); err != nil || n.Type != NodeParagraph {
+		t.Errorf("%s: Got: %s (%v), Expect: NodeParagraph", data, n.Type, err)
+	}
+	if err := json.Unmarshal([]byte(`{"type": "NodeUnknown"}`), &n); err == nil {
+		t.Error("Expected an error for an unknown NodeType")
+	}
+}
+
+func TestEnumListJSON(t *testing.T) {
+	data, err := json.Marshal(&EnumListNode{EnumType: enumListLowerRoman,
+		Affix: enumAffixParenthesisRight})
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := `"enumType":"enumListLowerRoman","affix":"enumAffixParenthesisRight"`
+	if !strings.Contains(string(data), exp) {
+		t.Errorf("json.Marshal: Got: %s, Expect: %s", data, exp)
+	}
+	var n EnumListNode
+	if err := json.Unmarshal(data, &n); err != nil ||
+		n.EnumType != enumListLowerRoman ||
+		n.Affix != enumAffixParenthesisRight {
+		t.Errorf("json.Unmarshal(%s): Got: %s, %s (err = %v)", data,
+			n.EnumType, n.Affix, err)
+	}
+}
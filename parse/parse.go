@@ -10,23 +10,35 @@
 package parse
 
 import (
-	"code.google.com/p/go.text/unicode/norm"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
 	"github.com/davecgh/go-spew/spew"
 	"github.com/demizer/go-elog"
+	"golang.org/x/text/unicode/norm"
 )
 
 // Used for debugging only
 var spd = spew.ConfigState{Indent: "\t"} //, DisableMethods: true}
 
-// systemMessageLevel implements four levels for messages and is used in
+// SystemMessageLevel implements four levels for messages and is used in
 // conjunction with the parserMessage type.
-type systemMessageLevel int
+type SystemMessageLevel int
 
 const (
-	levelInfo systemMessageLevel = iota
-	levelWarning
-	levelError
-	levelSevere
+	// LevelInfo is for messages that are informative only.
+	LevelInfo SystemMessageLevel = iota
+	// LevelWarning is for problems that still produce usable output.
+	LevelWarning
+	// LevelError is for problems that should be fixed by the author.
+	LevelError
+	// LevelSevere is for problems that may cause content to be lost.
+	LevelSevere
 )
 
 var systemMessageLevels = [...]string{
@@ -36,19 +48,49 @@ var systemMessageLevels = [...]string{
 	"SEVERE",
 }
 
-// String implments Stringer and return a string of the systemMessageLevel.
-func (s systemMessageLevel) String() string {
+// String implments Stringer and return a string of the SystemMessageLevel.
+func (s SystemMessageLevel) String() string {
+	if s < LevelInfo || s > LevelSevere {
+		return "SystemMessageLevel(" + strconv.Itoa(int(s)) + ")"
+	}
 	return systemMessageLevels[s]
 }
 
-// FromString returns the systemMessageLevel converted from the string name.
-func systemMessageLevelFromString(name string) systemMessageLevel {
+// ParseLevel returns the SystemMessageLevel named by name. The name is
+// matched case-insensitively against the level names ("info", "WARNING",
+// ...). The docutils numeric forms, "1" (info) through "4" (severe), are
+// also accepted.
+func ParseLevel(name string) (SystemMessageLevel, error) {
 	for num, sLvl := range systemMessageLevels {
-		if name == sLvl {
-			return systemMessageLevel(num)
+		if strings.EqualFold(name, sLvl) {
+			return SystemMessageLevel(num), nil
 		}
 	}
-	return -1
+	if n, err := strconv.Atoi(name); err == nil && n >= 1 && n <= 4 {
+		return SystemMessageLevel(n - 1), nil
+	}
+	return -1, fmt.Errorf("invalid system message level %q; valid levels "+
+		"are %s (or 1-4)", name, strings.Join(systemMessageLevels[:], ", "))
+}
+
+// MarshalText implements encoding.TextMarshaler. The level is encoded using
+// its name.
+func (s SystemMessageLevel) MarshalText() ([]byte, error) {
+	if s < LevelInfo || s > LevelSevere {
+		return nil, fmt.Errorf("invalid system message level %d", int(s))
+	}
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. Any form accepted by
+// ParseLevel is decoded.
+func (s *SystemMessageLevel) UnmarshalText(text []byte) error {
+	lvl, err := ParseLevel(string(text))
+	if err != nil {
+		return err
+	}
+	*s = lvl
+	return nil
 }
 
 // parserMessage implements messages generated by the parser. Parser messages
@@ -60,10 +102,33 @@ const (
 	infoOverlineTooShortForTitle
 	infoUnexpectedTitleOverlineOrTransition
 	infoUnderlineTooShortForTitle
+	infoNoBreakSpaceIndent
+	infoSectionBeyondMaxDepth
+	infoInvisibleControl
 	warningShortOverline
 	warningShortUnderline
 	warningExplicitMarkupWithUnIndent
+	warningBulletListWithUnIndent
+	warningEnumListWithUnIndent
+	warningFieldListWithUnIndent
+	warningOptionListWithUnIndent
+	warningDefinitionListWithUnIndent
+	warningBlockQuoteWithUnIndent
+	warningLiteralBlockWithUnIndent
+	warningLiteralBlockExpected
+	warningNonASCIIWhitespaceIndent
+	warningInvisibleControlRemoved
 	errorInvalidSectionOrTransitionMarker
+	errorDuplicateBibliographicField
+	errorInconsistentLiteralBlockQuoting
+	errorUnexpectedIndentation
+	errorTransitionBeginsSection
+	errorAdjacentTransitions
+	errorTransitionEndsDocument
+	errorUnknownDirectiveType
+	errorDirectiveContentExpected
+	errorDirectiveInvalidOption
+	errorSectionBeyondMaxDepth
 	severeUnexpectedSectionTitle
 	severeUnexpectedSectionTitleOrTransition
 	severeIncompleteSectionTitle
@@ -77,10 +142,33 @@ var parserErrors = [...]string{
 	"infoOverlineTooShortForTitle",
 	"infoUnexpectedTitleOverlineOrTransition",
 	"infoUnderlineTooShortForTitle",
+	"infoNoBreakSpaceIndent",
+	"infoSectionBeyondMaxDepth",
+	"infoInvisibleControl",
 	"warningShortOverline",
 	"warningShortUnderline",
 	"warningExplicitMarkupWithUnIndent",
+	"warningBulletListWithUnIndent",
+	"warningEnumListWithUnIndent",
+	"warningFieldListWithUnIndent",
+	"warningOptionListWithUnIndent",
+	"warningDefinitionListWithUnIndent",
+	"warningBlockQuoteWithUnIndent",
+	"warningLiteralBlockWithUnIndent",
+	"warningLiteralBlockExpected",
+	"warningNonASCIIWhitespaceIndent",
+	"warningInvisibleControlRemoved",
 	"errorInvalidSectionOrTransitionMarker",
+	"errorDuplicateBibliographicField",
+	"errorInconsistentLiteralBlockQuoting",
+	"errorUnexpectedIndentation",
+	"errorTransitionBeginsSection",
+	"errorAdjacentTransitions",
+	"errorTransitionEndsDocument",
+	"errorUnknownDirectiveType",
+	"errorDirectiveContentExpected",
+	"errorDirectiveInvalidOption",
+	"errorSectionBeyondMaxDepth",
 	"severeUnexpectedSectionTitle",
 	"severeUnexpectedSectionTitleOrTransition",
 	"severeIncompleteSectionTitle",
@@ -95,6 +183,24 @@ func (p parserMessage) String() string {
 	return parserErrors[p]
 }
 
+// MarshalJSON implements json.Marshaler. The parserMessage is encoded by its
+// name, such as "severeIncompleteSectionTitle", so that encoded messages do
+// not depend on the numbers of the messages.
+func (p parserMessage) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler. The parserMessage can be given by
+// its name or by its number.
+func (p *parserMessage) UnmarshalJSON(data []byte) error {
+	num, err := unmarshalName(data, parserErrors[:])
+	if err != nil {
+		return fmt.Errorf("parserMessage: %s", err)
+	}
+	*p = parserMessage(num)
+	return nil
+}
+
 // Message returns the message of the parserMessage as a string.
 func (p parserMessage) Message() (s string) {
 	switch p {
@@ -108,6 +214,13 @@ func (p parserMessage) Message() (s string) {
 	case infoUnderlineTooShortForTitle:
 		s = "Possible title underline, too short for the title.\n" +
 			"Treating it as ordinary text because it's so short."
+	case infoNoBreakSpaceIndent:
+		s = "No-break space in indentation treated as a space."
+	case infoSectionBeyondMaxDepth:
+		s = "Section exceeds the maximum section depth.\n" +
+			"Treating the title as a paragraph of the enclosing section."
+	case infoInvisibleControl:
+		s = "Invisible control character in text."
 	case warningShortOverline:
 		s = "Title overline too short."
 	case warningShortUnderline:
@@ -115,8 +228,57 @@ func (p parserMessage) Message() (s string) {
 	case warningExplicitMarkupWithUnIndent:
 		s = "Explicit markup ends without a blank line; " +
 			"unexpected unindent."
+	case warningBulletListWithUnIndent:
+		s = "Bullet list ends without a blank line; " +
+			"unexpected unindent."
+	case warningEnumListWithUnIndent:
+		s = "Enumerated list ends without a blank line; " +
+			"unexpected unindent."
+	case warningFieldListWithUnIndent:
+		s = "Field list ends without a blank line; " +
+			"unexpected unindent."
+	case warningOptionListWithUnIndent:
+		s = "Option list ends without a blank line; " +
+			"unexpected unindent."
+	case warningDefinitionListWithUnIndent:
+		s = "Definition list ends without a blank line; " +
+			"unexpected unindent."
+	case warningBlockQuoteWithUnIndent:
+		s = "Block quote ends without a blank line; " +
+			"unexpected unindent."
+	case warningLiteralBlockWithUnIndent:
+		s = "Literal block ends without a blank line; " +
+			"unexpected unindent."
+	case warningLiteralBlockExpected:
+		s = "Literal block expected; none found."
+	case warningNonASCIIWhitespaceIndent:
+		s = "Non-ASCII whitespace at the start of a line is " +
+			"treated as text, not indentation."
+	case warningInvisibleControlRemoved:
+		s = "Invisible control character removed from text."
 	case errorInvalidSectionOrTransitionMarker:
 		s = "Invalid section title or transition marker."
+	case errorDuplicateBibliographicField:
+		s = "Duplicate bibliographic field."
+	case errorInconsistentLiteralBlockQuoting:
+		s = "Inconsistent literal block quoting."
+	case errorUnexpectedIndentation:
+		s = "Unexpected indentation."
+	case errorTransitionBeginsSection:
+		s = "Document or section may not begin with a transition."
+	case errorAdjacentTransitions:
+		s = "At least one body element must separate transitions; " +
+			"adjacent transitions are not allowed."
+	case errorTransitionEndsDocument:
+		s = "Document may not end with a transition."
+	case errorUnknownDirectiveType:
+		s = "Unknown directive type."
+	case errorDirectiveContentExpected:
+		s = "Content block expected for the directive; none found."
+	case errorDirectiveInvalidOption:
+		s = "Error in directive: invalid option value."
+	case errorSectionBeyondMaxDepth:
+		s = "Section exceeds the maximum section depth."
 	case severeUnexpectedSectionTitle:
 		s = "Unexpected section title."
 	case severeUnexpectedSectionTitleOrTransition:
@@ -133,18 +295,19 @@ func (p parserMessage) Message() (s string) {
 	return
 }
 
-// Level returns the parserMessage level.
-func (p parserMessage) Level() (s systemMessageLevel) {
-	lvl := int(p)
+// Level returns the parserMessage level. The parserMessage constants are
+// grouped by level, so the level is determined by the last message of each
+// group.
+func (p parserMessage) Level() (s SystemMessageLevel) {
 	switch {
-	case lvl > 0 && lvl <= 3:
-		s = levelInfo
-	case lvl <= 6:
-		s = levelWarning
-	case lvl == 7:
-		s = levelError
-	case lvl >= 8:
-		s = levelSevere
+	case p > parserMessageNil && p <= infoInvisibleControl:
+		s = LevelInfo
+	case p <= warningInvisibleControlRemoved:
+		s = LevelWarning
+	case p <= errorSectionBeyondMaxDepth:
+		s = LevelError
+	default:
+		s = LevelSevere
 	}
 	return
 }
@@ -168,73 +331,53 @@ type sectionLevels struct {
 	levels          []*sectionLevel
 }
 
-// FindByRune loops through the sectionLevels to find a section using a Rune as
-// the key. If the section is found, a pointer to the SectionNode is returned.
-func (s *sectionLevels) FindByRune(rChar rune) *sectionLevel {
+// FindByStyle loops through the sectionLevels to find a section level using
+// the adornment style of a section as the key. The style is the underline rune
+// and whether the section has an overline; as in docutils, a rune used with an
+// overline is a different style than the rune used as an underline only. If
+// the section level is found, a pointer to the sectionLevel is returned.
+func (s *sectionLevels) FindByStyle(rChar rune, overLine bool) *sectionLevel {
 	for _, sec := range s.levels {
-		if sec.rChar == rChar {
+		if sec.rChar == rChar && sec.overLine == overLine {
 			return sec
 		}
 	}
 	return nil
 }
 
-// Add determines if the underline rune in the sec argument matches any
+// Add determines if the adornment style of the sec argument matches any
 // existing sectionLevel in sectionLevels. Add also checks the section level
 // ordering is correct and returns a severeTitleLevelInconsistent parserMessage
-// if inconsistencies are found.
+// if inconsistencies are found. A new style can only begin the level below the
+// last section, and an existing style can only be used for a section at most
+// one level below the last section.
 func (s *sectionLevels) Add(sec *SectionNode) (err parserMessage) {
-	level := 1
-	secLvl := s.FindByRune(sec.UnderLine.Rune)
+	oLine := sec.OverLine != nil
+	secLvl := s.FindByStyle(sec.UnderLine.Rune, oLine)
+	lastLevel := 0
+	if s.lastSectionNode != nil {
+		lastLevel = s.lastSectionNode.Level
+	}
 
-	// Local function for creating a sectionLevel
-	var newSectionLevel = func() {
-		var oLine bool
-		if sec.OverLine != nil {
-			oLine = true
+	if secLvl == nil {
+		if len(s.levels) != lastLevel {
+			// The level below the last section already has a
+			// different style.
+			return severeTitleLevelInconsistent
 		}
-		log.Debugln("Creating new sectionLevel:", level)
+		log.Debugln("Creating new sectionLevel:", len(s.levels)+1)
 		secLvl = &sectionLevel{
 			rChar: sec.UnderLine.Rune,
-			level: level, overLine: oLine,
+			level: len(s.levels) + 1, overLine: oLine,
 		}
 		s.levels = append(s.levels, secLvl)
-		// secLvl.sections = append(secLvl.sections, sec)
-	}
-
-	if secLvl == nil {
-		if s.lastSectionNode != nil {
-			// Check if the provisional level of sec is already in
-			// sectionLevels; if it is and the adornment characters
-			// don't match, then we have an inconsistent level
-			// error.
-			level = s.lastSectionNode.Level + 1
-			nextLevel := s.SectionLevelByLevel(level)
-			if nextLevel != nil &&
-				nextLevel.rChar != sec.UnderLine.Rune {
-				return severeTitleLevelInconsistent
-			}
-		} else {
-			level = len(s.levels) + 1
-		}
-		newSectionLevel()
-	} else {
-		if secLvl.overLine && sec.OverLine == nil ||
-			!secLvl.overLine && sec.OverLine != nil {
-			// If sec has an OverLine, but the matching
-			// sectionLevel with the same Rune as sec does not have
-			// an OverLine, then they are not in the same
-			// sectionLevel, and visa versa.
-			level = len(s.levels) + 1
-			newSectionLevel()
-		} else {
-			log.Debugln("Using sectionLevel:", secLvl.level)
-			level = secLvl.level
-		}
+	} else if secLvl.level > lastLevel+1 {
+		return severeTitleLevelInconsistent
 	}
+	log.Debugln("Using sectionLevel:", secLvl.level)
 
 	secLvl.sections = append(secLvl.sections, sec)
-	sec.Level = level
+	sec.Level = secLvl.level
 	s.lastSectionNode = sec
 	return
 }
@@ -267,14 +410,85 @@ exit:
 	return
 }
 
+// ParseOption configures optional behavior of the parser. Options are passed
+// to Parse.
+type ParseOption func(*Tree)
+
+// WithNBSPIndent treats the no-break space (U+00A0) as an ordinary space when
+// it appears in the indentation of a line. By default, as in docutils, a
+// no-break space is a text character and does not indent the line.
+func WithNBSPIndent() ParseOption {
+	return func(t *Tree) { t.nbspIndent = true }
+}
+
+// WithBidiControlsRejected removes zero-width and bidirectional control
+// characters from paragraphs and section titles, and reports them with a
+// warning. By default, they are kept and reported with an info message. These
+// characters are invisible but change how the text around them is displayed,
+// so they can be used to make text read differently than it is written.
+func WithBidiControlsRejected() ParseOption {
+	return func(t *Tree) { t.rejectControls = true }
+}
+
+// WithRawFidelity keeps the spaces and tabs at the end of the lines of input.
+// By default, as in docutils, they are removed before lexing, so no text of
+// the parse tree ends with whitespace.
+func WithRawFidelity() ParseOption {
+	return func(t *Tree) { t.rawFidelity = true }
+}
+
+// DepthMode selects how sections nested deeper than the limit set by
+// WithMaxSectionDepth are handled.
+type DepthMode int
+
+const (
+	// DepthStrict keeps sections beyond the limit and reports each of them
+	// with an ERROR system message.
+	DepthStrict DepthMode = iota
+
+	// DepthFlatten replaces each section beyond the limit with a paragraph
+	// containing its title. The paragraph, and the content of the section,
+	// is appended to the enclosing section at the limit. Each replaced
+	// section is reported with an INFO system message.
+	DepthFlatten
+)
+
+// WithMaxSectionDepth limits the nesting of sections to n levels, for output
+// formats that support only a few heading levels. mode selects how deeper
+// sections are handled. A limit less than 1 disables the check.
+func WithMaxSectionDepth(n int, mode DepthMode) ParseOption {
+	return func(t *Tree) {
+		t.maxSectionDepth = n
+		t.depthMode = mode
+	}
+}
+
 // Parse is the entry point for the reStructuredText parser. Errors generated
-// by the parser are returned as a NodeList.
-func Parse(name, text string) (t *Tree, errors NodeList) {
+// by the parser are returned as a NodeList. Invalid UTF-8 in text is replaced
+// with U+FFFD.
+func Parse(name, text string, opts ...ParseOption) (t *Tree, errors NodeList) {
+	var start time.Time
 	t = New(name, text)
+	for _, opt := range opts {
+		opt(t)
+	}
+	if t.metrics != nil {
+		*t.metrics = Metrics{InputSize: len(text)}
+		start = time.Now()
+	}
+	if !utf8.ValidString(text) {
+		text = strings.ToValidUTF8(text, string(utf8.RuneError))
+	}
 	if !norm.NFC.IsNormalString(text) {
 		text = norm.NFC.String(text)
 	}
+	if t.metrics != nil {
+		t.metrics.Normalize = time.Since(start)
+	}
 	t.Parse(text, t)
+	if t.metrics != nil {
+		t.metrics.Total = time.Since(start)
+	}
 	errors = t.Messages
 	return
 }
@@ -313,8 +527,170 @@ type Tree struct {
 	id                 int            // Consecutive id of the node in the tree
 	indentWidth        int
 	indentLevel        int
-	openDefinitionList *NodeList
-	openBulletList     *NodeList
+	definitions        []*definitionLevel // Open definition lists
+	bullets            []*bulletLevel     // Open bullet lists
+	enums              []*enumLevel       // Open enumerated lists
+	openOptionList     *OptionListNode
+	openOptionListItem *OptionListItemNode
+	openFieldList      *FieldListNode // Field list being parsed
+	openField          *FieldNode     // Last field of openFieldList
+	quotes             []*quoteIndent // Open block quotes, innermost last
+	quoteTarget        *NodeList      // Contains the outermost block quote
+	nbspIndent         bool           // Treat U+00A0 as indentation
+	rejectControls     bool           // Remove invisible controls
+	indentNotices      []indentNotice // Non-ASCII whitespace in indents
+	indentNotice       *indentNotice  // The notice being reported
+	controlNotice      *controlNotice // Invisible control in a paragraph
+	directive          *directiveItem // The directive being reported
+	metrics            *Metrics       // Parse statistics, if requested
+	maxSectionDepth    int            // Deepest section level, if > 0
+	depthMode          DepthMode      // Handling of deeper sections
+	docinfo            bool           // Convert the leading field list
+	frontMatter        bool           // Find the leading comment
+	literalExpected    bool           // A paragraph ended with "::"
+	rawFidelity        bool           // Keep trailing whitespace
+	docinfoDone        bool           // The docinfo is converted
+	bodies             []*bodyList    // Body lists checked, root first
+	events             EventHandler   // Receives the checked nodes, if set
+	sentNodes          int            // Nodes sent to events
+	err                error          // The error that stopped parsing
+
+	// FrontMatter is the comment beginning the document, if requested
+	// with WithFrontMatterComment.
+	FrontMatter *FrontMatter
+}
+
+// quoteIndent is an open block quote and the indent of its content.
+type quoteIndent struct {
+	node       *BlockQuoteNode
+	indent     int  // Width of the indent of the block quote text
+	attributed bool // The block quote ended with an attribution
+}
+
+// bulletLevel is an open bullet list and its last item. The bullet lists
+// nested in the item follow it in Tree.bullets.
+type bulletLevel struct {
+	node   *BulletListNode
+	item   *BulletListItemNode
+	indent int       // Column of the bullets, from 0
+	body   int       // Column of the body of the item, from 0
+	target *NodeList // Contains the list
+}
+
+// enumLevel is an open enumerated list and its last item. The enumerated
+// lists nested in the item follow it in Tree.enums.
+type enumLevel struct {
+	node    *EnumListNode
+	item    *EnumListItemNode
+	indent  int       // Column of the enumerators, from 0
+	body    int       // Column of the body of the item, from 0
+	target  *NodeList // Contains the list
+	ordinal int       // Ordinal of the last item
+	auto    bool      // The list has auto items
+}
+
+// definitionLevel is an open definition list. The definition lists nested in
+// the definition of its last item follow it in Tree.definitions.
+type definitionLevel struct {
+	list   *NodeList // The items of the list
+	indent int       // Column of the terms, from 0
+	target *NodeList // Contains the list
+}
+
+// indentNotice records a non-ASCII whitespace character found in the
+// indentation of a line of input.
+type indentNotice struct {
+	line   Line
+	column int    // The column of r, counted in runes from 1
+	r      rune   // The whitespace character
+	text   string // The line of input containing r
+}
+
+// IsInvisibleControl returns true if r is a zero-width character (U+200B to
+// U+200D), a bidirectional mark (U+200E, U+200F), or a bidirectional
+// embedding, override, or isolate control (U+202A to U+202E, U+2066 to
+// U+2069).
+func IsInvisibleControl(r rune) bool {
+	return r >= '\u200b' && r <= '\u200f' || r >= '\u202a' && r <= '\u202e' ||
+		r >= '\u2066' && r <= '\u2069'
+}
+
+// controlNotice records the first invisible control character found in a
+// paragraph.
+type controlNotice struct {
+	line   Line
+	column int  // The column of r in the input, counted in runes from 1
+	r      rune // The control character
+}
+
+// findControl returns a controlNotice for the first invisible control
+// character in text, the text of the lines of input beginning at line, or nil
+// if there is none.
+func (t *Tree) findControl(line Line, text string) *controlNotice {
+	if strings.IndexFunc(text, IsInvisibleControl) < 0 {
+		return nil
+	}
+	for num, l := range strings.Split(text, "\n") {
+		if strings.IndexFunc(l, IsInvisibleControl) < 0 {
+			continue
+		}
+		// The text of the paragraph does not contain the indentation,
+		// so the column is found in the input.
+		src := l
+		if n := int(line) - 1 + num; n < len(t.lex.lines) {
+			src = t.lex.lines[n]
+		}
+		column := 0
+		for _, r := range src {
+			column++
+			if IsInvisibleControl(r) {
+				return &controlNotice{line + Line(num), column, r}
+			}
+		}
+	}
+	return nil
+}
+
+// removeControls returns text without its invisible control characters.
+func removeControls(text string) string {
+	return strings.Map(func(r rune) rune {
+		if IsInvisibleControl(r) {
+			return -1
+		}
+		return r
+	}, text)
+}
+
+// findIndentNotices returns an indentNotice for each line of text that
+// contains non-ASCII whitespace in its leading whitespace. When nbspIndent is
+// set, a no-break space is only reported if it is the only kind of non-ASCII
+// whitespace found on the line.
+func findIndentNotices(text string, nbspIndent bool) (n []indentNotice) {
+	for num, line := range strings.Split(text, "\n") {
+		var found *indentNotice
+		column := 0
+		for _, r := range line {
+			column++
+			if !unicode.IsSpace(r) {
+				break
+			}
+			if r <= unicode.MaxASCII {
+				continue
+			}
+			if r == '\u00a0' && nbspIndent {
+				if found == nil {
+					found = &indentNotice{Line(num + 1), column, r, line}
+				}
+				continue
+			}
+			found = &indentNotice{Line(num + 1), column, r, line}
+			break
+		}
+		if found != nil {
+			n = append(n, *found)
+		}
+	}
+	return
 }
 
 // startParse initializes the parser, using the lexer.
@@ -327,9 +703,25 @@ func (t *Tree) startParse(lex *lexer) {
 // Top level Parse function.
 func (t *Tree) Parse(text string, treeSet *Tree) (tree *Tree) {
 	log.Debugln("START")
-	t.startParse(lex(t.Name, text))
+	var mark time.Time
+	if t.metrics != nil {
+		mark = time.Now()
+	}
 	t.text = text
+	t.indentNotices = findIndentNotices(text, t.nbspIndent)
+	if t.metrics != nil {
+		t.metrics.Scan = time.Since(mark)
+		mark = time.Now()
+	}
+	l := newLexer(t.Name, text, t.rawFidelity)
+	l.nbspIndent = t.nbspIndent
+	t.startParse(l)
 	t.parse(treeSet)
+	t.checkBody(true)
+	if t.metrics != nil {
+		t.metrics.Parse = time.Since(mark)
+		t.collectMetrics()
+	}
 	log.Debugln("END")
 	return t
 }
@@ -341,39 +733,122 @@ func (t *Tree) parse(tree *Tree) {
 
 	t.nodeTarget = &t.Nodes
 
-	for t.peek(1).Type != itemEOF {
+	var last *item
+	var stalled int
+	for p := t.peek(1); p.Type != itemEOF; p = t.peek(1) {
+		t.checkBody(false)
+		if p.Type == itemError {
+			// The lexer stopped, there are no more items.
+			log.Errorln(p.Text)
+			t.err = fmt.Errorf("%s: %s", t.Name, p.Text)
+			break
+		}
+		if p != last {
+			last, stalled = p, 0
+		} else if stalled++; maxStalledStates > 0 && stalled > maxStalledStates {
+			// The token buffer was backed up to the same item
+			// again and again.
+			t.err = fmt.Errorf("%s: parser made no progress at line %d",
+				t.Name, p.Line)
+			log.Errorln(t.err)
+			break
+		}
 		var n interface{}
 
 		token := t.next(1)
-		log.Infof("\nParser got token: %#+v\n\n", token)
+		if infoLogEnabled() {
+			log.Infof("Parser got token: %s\n", token.logString())
+		}
+
+		if token.Type == itemSpace && t.peek(1).Type == itemLiteralBlock {
+			t.literalExpected = false
+			t.literalBlock()
+			continue
+		}
+		if token.Type == itemLiteralBlock {
+			t.literalExpected = false
+			t.quotedLiteralBlock(token)
+			continue
+		}
+		if t.literalExpected && token.Type != itemBlankLine {
+			t.literalExpected = false
+			t.nodeTarget.append(t.systemMessage(warningLiteralBlockExpected))
+		}
+
+		if token.StartPosition == 1 && token.Type != itemBlankLine {
+			t.closeIndented(token)
+		}
 
 		// FIXME: Hackish. Need to find a better way...
 		if t.indentLevel > 0 && token.StartPosition == 1 &&
 			token.Type != itemSpace && token.Type != itemBlankLine &&
-			token.Type != itemDefinitionTerm {
+			(token.Type != itemDefinitionTerm ||
+				len(t.definitions) == 0) {
 			t.indentLevel = 0
-			t.openDefinitionList = nil
-			t.nodeTarget = &t.Nodes
+			t.nodeTarget = t.sectionTarget()
+			t.closeDefinitionList()
+			if len(t.quotes) > 0 {
+				t.closeBlockQuote()
+			}
+			if token.Type != itemBullet {
+				t.closeBulletList()
+			}
+		}
+
+		t.indentMessages(token.Line)
+
+		if t.openFieldList != nil {
+			if token.Type == itemSpace &&
+				t.peek(1).Type == itemFieldBody {
+				// The body of the field continues after a blank
+				// line.
+				t.fieldBody(t.next(1))
+				continue
+			}
+			if token.Type != itemFieldList &&
+				token.Type != itemBlankLine {
+				t.closeFieldList()
+			}
+		}
+
+		if t.openOptionList != nil {
+			if token.Type == itemSpace &&
+				t.peek(1).Type == itemOptionDescription {
+				// The description continues after a blank line.
+				t.optionDescription(t.next(1))
+				continue
+			}
+			if token.Type != itemOption && token.Type != itemBlankLine {
+				t.closeOptionList()
+			}
 		}
 
 		switch token.Type {
 		case itemParagraph:
 			n = t.paragraph(token)
+			if n == nil {
+				// The paragraph was only a literal block marker
+				continue
+			}
 		case itemTransition:
 			n = newTransition(token, &t.id)
 		case itemCommentMark:
 			n = t.comment(token)
+		case itemDirective:
+			n = t.directiveBlock(token)
 		case itemSectionAdornment:
 			n = t.section(token)
-		case itemEnumListArabic:
-			n = t.enumList(token)
-			// FIXME: This is only until enumerated list are
-			// properly implemented.
 			if n == nil {
+				// The adornment was text, and only a literal
+				// block marker
 				continue
 			}
+		case itemEnumListArabic, itemEnumListAlpha, itemEnumListRoman,
+			itemEnumListAuto, itemEnumListAffix:
+			n = t.enumListItem(token)
+			t.indentLevel++
 		case itemSpace:
-			if t.peekBack(1).Type == itemBlankLine && t.indentLevel == 0 {
+			if t.followsBlankLine() && t.indentLevel == 0 {
 				n = t.blockquote(token)
 			}
 			if n == nil {
@@ -383,36 +858,80 @@ func (t *Tree) parse(tree *Tree) {
 				// nodeTarget below.
 				continue
 			}
+		case itemFieldList:
+			n = t.fieldList(token)
+			if n == nil {
+				continue
+			}
+		case itemOption:
+			n = t.optionList(token)
+			if n == nil {
+				continue
+			}
 		case itemTitle, itemBlankLine:
 			// itemTitle is consumed when evaluating
 			// itemSectionAdornment
 			continue
+		case itemDoctestBlock:
+			n = t.doctestBlock(token)
 		case itemBlockQuote:
+			if p := t.peekBack(2); p != nil &&
+				p.Type == itemParagraph {
+				// The block quote follows text without a blank
+				// line.
+				m := t.systemMessage(errorUnexpectedIndentation)
+				m.(*SystemMessageNode).Line = token.Line
+				t.nodeTarget.append(m)
+			}
 			n = t.blockquote(token)
+			if n == nil {
+				continue
+			}
+		case itemAttribution:
+			n = t.attribution(token)
+			if n == nil {
+				continue
+			}
 		case itemDefinitionTerm:
-			if t.openDefinitionList == nil && t.indentLevel == 0 {
+			col := int(token.StartPosition) - 1
+			d := t.innerDefinition()
+			if d == nil || d.indent < col {
+				// A term indented beyond the open list is in
+				// the definition of its last item.
 				n = t.definitionList(token)
-				t.openDefinitionList = &n.(*DefinitionListNode).NodeList
+				list := n.(*DefinitionListNode)
+				t.definitions = append(t.definitions,
+					&definitionLevel{
+						list:   &list.NodeList,
+						indent: col,
+						target: t.nodeTarget,
+					})
 				break
 			}
 			n = t.definitionListItem(token)
-			t.nodeTarget = t.openDefinitionList
+			t.nodeTarget = t.innerDefinition().list
 			t.indentLevel++
 		case itemBullet:
-			// FIXME: This will get fixed when I am ready for full
-			// bullet list support.
-			var nn Node
-			if t.openBulletList == nil && t.indentLevel == 0 {
-				nn = t.bulletList(token)
-				t.nodeTarget.append(nn.(Node))
-				t.openBulletList = &nn.(*BulletListNode).NodeList
-			}
 			n = t.bulletListItem(token)
-			t.nodeTarget = t.openBulletList
 			t.indentLevel++
+		default:
+			// The body of an element whose beginning was part of
+			// an error, such as the next line of a field, is text.
+			token.Type = itemParagraph
+			if n = t.paragraph(token); n == nil {
+				continue
+			}
 		}
 
 		t.nodeTarget.append(n.(Node))
+		if t.controlNotice != nil {
+			m := infoInvisibleControl
+			if t.rejectControls {
+				m = warningInvisibleControlRemoved
+			}
+			t.nodeTarget.append(t.systemMessage(m))
+			t.controlNotice = nil
+		}
 		// Set the loop to append items to the NodeList of the new
 		// section
 		switch n.(Node).NodeType() {
@@ -424,8 +943,16 @@ func (t *Tree) parse(tree *Tree) {
 			t.nodeTarget = &n.(*DefinitionListItemNode).Definition.NodeList
 		case NodeBulletListItem:
 			t.nodeTarget = &n.(*BulletListItemNode).NodeList
+		case NodeEnumListItem:
+			t.nodeTarget = &n.(*EnumListItemNode).NodeList
 		}
 	}
+	if t.literalExpected {
+		m := t.systemMessage(warningLiteralBlockExpected)
+		m.(*SystemMessageNode).Line = t.peek(1).Line
+		t.nodeTarget.append(m)
+	}
+	t.indentMessages(t.peek(1).Line + 1)
 	log.Debugln("END")
 }
 
@@ -458,14 +985,15 @@ func (t *Tree) peekBackTo(item itemElement) (tok *item) {
 // peek looks ahead in the token stream a number of positions (pos) and gets
 // the next token from the lexer. A pointer to the token is kept in the
 // Tree.token buffer. If a token pointer already exists in the buffer, that
-// token is used instead and no tokens are received the the lexer stream
-// (channel).
+// token is used instead and no tokens are received the the lexer stream.
 func (t *Tree) peek(pos int) *item {
 	nItem := t.token[zed]
 	for i := 1; i <= pos; i++ {
 		if t.token[zed+i] != nil {
 			nItem = t.token[zed+i]
-			log.Debugf("Using %#+v\n", nItem)
+			if debugLogEnabled() {
+				log.Debugf("Using %s\n", nItem.logString())
+			}
 			continue
 		} else {
 			if t.lex == nil {
@@ -495,7 +1023,7 @@ func (t *Tree) peekSkip(iSkip itemElement) *item {
 }
 
 // next is the workhorse of the parser. It is repsonsible for getting the next
-// token from the lexer stream (channel). If the next token already exists in
+// token from the lexer stream. If the next token already exists in
 // the token buffer, than the token buffer is shifted left and the pointer to
 // the "zed" token is returned. pos specifies the number of times to call next.
 func (t *Tree) next(pos int) *item {
@@ -516,6 +1044,25 @@ func (t *Tree) next(pos int) *item {
 	return t.token[zed]
 }
 
+// skipLine moves the token buffer past the remaining tokens of line.
+func (t *Tree) skipLine(line Line) {
+	for p := t.peek(1); p != nil && p.Type != itemEOF && p.Line == line; p = t.peek(1) {
+		t.next(1)
+	}
+}
+
+// dropTokens removes n tokens from the buffer beginning at the position pos
+// after the current token. The following tokens are moved down, and the slots
+// left at the end are read from the lexer again by peek.
+func (t *Tree) dropTokens(pos, n int) {
+	for x := zed + pos; x < len(t.token); x++ {
+		t.token[x] = nil
+		if x+n < len(t.token) {
+			t.token[x] = t.token[x+n]
+		}
+	}
+}
+
 // clearTokens sets tokens from begin to end to nil.
 func (t *Tree) clearTokens(begin, end int) {
 	for i := begin; i <= end; i++ {
@@ -542,7 +1089,14 @@ func (t *Tree) section(i *item) Node {
 			t.next(2)
 			bTok := t.peekBack(1)
 			if bTok != nil && bTok.Type == itemSpace {
-				t.next(2)
+				// Move to the underline, which may not be
+				// indented like the title.
+				if t.peek(1).Type == itemSpace {
+					t.next(1)
+				}
+				if t.peek(1).Type == itemSectionAdornment {
+					t.next(1)
+				}
 				m := infoUnexpectedTitleOverlineOrTransition
 				return t.systemMessage(m)
 			}
@@ -569,6 +1123,15 @@ func (t *Tree) section(i *item) Node {
 			case itemSectionAdornment:
 				underAdorn = tTok
 				break loop
+			case itemEOF:
+				t.backup()
+				return t.systemMessage(severeIncompleteSectionTitle)
+			default:
+				// Any other element in place of the underline is
+				// part of the error, as in docutils.
+				m := t.systemMessage(severeMissingMatchingUnderlineForOverline)
+				t.skipLine(tTok.Line)
+				return m
 			}
 		}
 	} else if pBack != nil &&
@@ -582,6 +1145,10 @@ func (t *Tree) section(i *item) Node {
 				m := severeUnexpectedSectionTitle
 				return t.systemMessage(m)
 			}
+			// An indented adornment not under a title is text,
+			// nil is returned if it is a literal block marker.
+			i.Type = itemParagraph
+			return t.paragraph(i)
 		} else if tZedLen < 3 && tZedLen != pBack.Length {
 			// Short underline
 			return t.systemMessage(infoUnderlineTooShortForTitle)
@@ -595,15 +1162,27 @@ func (t *Tree) section(i *item) Node {
 		// If a section contains an itemParagraph, it is because the
 		// underline is missing, therefore we generate an error based
 		// on what follows the itemParagraph.
-		t.next(2) // Move the token buffer past the error tokens
 		if tZedLen < 3 && tZedLen != pFor.Length {
-			t.backup()
+			t.next(1)
 			return t.systemMessage(infoOverlineTooShortForTitle)
-		} else if p := t.peek(1); p != nil && p.Type == itemBlankLine {
-			m := severeMissingMatchingUnderlineForOverline
-			return t.systemMessage(m)
 		}
-		return t.systemMessage(severeIncompleteSectionTitle)
+		// Move the token buffer to the title
+		t.next(1)
+		if t.token[zed].Type == itemSpace {
+			t.next(1)
+		}
+		p := t.peek(1)
+		if p == nil || p.Type == itemEOF {
+			return t.systemMessage(severeIncompleteSectionTitle)
+		} else if p.Type != itemBlankLine {
+			// As in docutils, the text line in place of the underline
+			// is part of the error.
+			t.next(1)
+			m := t.systemMessage(severeMissingMatchingUnderlineForOverline)
+			t.skipLine(p.Line)
+			return m
+		}
+		return t.systemMessage(severeMissingMatchingUnderlineForOverline)
 	} else if pFor != nil && pFor.Type == itemSectionAdornment {
 		// Missing section title
 		t.next(1) // Move the token buffer past the error token
@@ -612,6 +1191,10 @@ func (t *Tree) section(i *item) Node {
 		// Missing underline and at EOF
 		return t.systemMessage(errorInvalidSectionOrTransitionMarker)
 	}
+	if title == nil {
+		// The adornment is not next to a title
+		return t.systemMessage(errorInvalidSectionOrTransitionMarker)
+	}
 
 	if overAdorn != nil &&
 		overAdorn.Text != underAdorn.Text {
@@ -632,6 +1215,15 @@ func (t *Tree) section(i *item) Node {
 	}
 
 	sec.Level = t.sectionLevels.lastSectionNode.Level
+	if t.maxSectionDepth > 0 && sec.Level > t.maxSectionDepth &&
+		t.depthMode == DepthFlatten {
+		log.Debugln("Flattening section beyond maximum depth")
+		t.id = undoID
+		lSec := t.sectionLevels.LastSectionByLevel(t.maxSectionDepth)
+		t.nodeTarget = &lSec.NodeList
+		t.nodeTarget.append(t.systemMessage(infoSectionBeyondMaxDepth))
+		return newParagraph(title, &t.id)
+	}
 	if sec.Level == 1 {
 		log.Debugln("Setting nodeTarget to Tree.Nodes!")
 		t.nodeTarget = &t.Nodes
@@ -653,6 +1245,10 @@ func (t *Tree) section(i *item) Node {
 		oLen = indent.Length + title.Length
 	}
 
+	if t.maxSectionDepth > 0 && sec.Level > t.maxSectionDepth {
+		m := errorSectionBeyondMaxDepth
+		sec.NodeList = append(sec.NodeList, t.systemMessage(m))
+	}
 	if overAdorn != nil && oLen > overAdorn.Length {
 		m := warningShortOverline
 		sec.NodeList = append(sec.NodeList, t.systemMessage(m))
@@ -660,12 +1256,23 @@ func (t *Tree) section(i *item) Node {
 		m := warningShortUnderline
 		sec.NodeList = append(sec.NodeList, t.systemMessage(m))
 	}
+	// As in a paragraph, an invisible control in the title is reported.
+	t.controlNotice = t.findControl(title.Line, title.Text)
+	if t.controlNotice != nil {
+		m := infoInvisibleControl
+		if t.rejectControls {
+			m = warningInvisibleControlRemoved
+			sec.Title.Text = removeControls(sec.Title.Text)
+			sec.Title.Length = utf8.RuneCountInString(sec.Title.Text)
+		}
+		sec.NodeList = append(sec.NodeList, t.systemMessage(m))
+		t.controlNotice = nil
+	}
 	log.Debugln("END")
 	return sec
 }
 
 func (t *Tree) comment(i *item) Node {
-	var n Node
 	if t.peek(1).Type == itemBlankLine {
 		log.Debugln("Found empty comment block")
 		return newComment(&item{
@@ -673,46 +1280,75 @@ func (t *Tree) comment(i *item) Node {
 			Line:          i.Line,
 		}, &t.id)
 	}
-	nSpace := t.peek(1)
-	if nSpace != nil && nSpace.Type != itemSpace {
-		// The comment element itself is valid, but we need to add it
-		// to the NodeList before the systemMessage.
+	if nPara := t.peek(2); t.peek(1).Type != itemSpace ||
+		nPara.Type != itemParagraph ||
+		nPara.Line != i.Line && !t.commentContinues(i) {
+		// The marker is directly followed by another element, so the
+		// comment is empty. The comment element itself is valid, but
+		// we need to add it to the NodeList before the systemMessage.
 		log.Debugln("Missing space after comment mark! " +
 			"(warningExplicitMarkupWithUnIndent)")
-		n = newComment(&item{Line: i.Line}, &t.id)
-		t.nodeTarget.append(n)
+		t.nodeTarget.append(newComment(&item{
+			StartPosition: i.StartPosition,
+			Line:          i.Line,
+		}, &t.id))
 		return t.systemMessage(warningExplicitMarkupWithUnIndent)
 	}
-	nPara := t.peek(2)
-	if nPara != nil && nPara.Type == itemParagraph {
-		t.next(2)
-		if t.peek(1).Type == itemSpace && t.peek(2).Type == itemParagraph {
-			log.Debugln("Found NodeComment block")
+	nPara := t.next(2)
+	blank, lines := 0, 1
+	for {
+		if t.peek(1).Type == itemBlankLine {
+			t.next(1)
+			blank++
+		} else if t.commentContinues(i) {
 			t.next(2)
-			for {
-				nPara.Text += "\n" + t.token[zed].Text
-				if t.peek(1).Type == itemSpace &&
-					t.peek(2).Type == itemParagraph {
-					t.next(2)
-				} else {
-					break
-				}
-			}
-			nPara.Length = len(nPara.Text)
-		} else if z := t.peek(1).Type; z != itemBlankLine &&
-			z != itemCommentMark && z != itemEOF {
-			// A valid comment contains a blank line after the
-			// comment block
-			log.Debugln("Found warningExplicitMarkupWithUnIndent")
-			n = newComment(nPara, &t.id)
-			t.nodeTarget.append(n)
-			return t.systemMessage(warningExplicitMarkupWithUnIndent)
+			nPara.Text += strings.Repeat("\n", blank+1) +
+				t.token[zed].Text
+			blank = 0
+			lines++
 		} else {
-			log.Debugln("Found NodeComment")
+			break
+		}
+	}
+	if lines > 1 {
+		log.Debugln("Found NodeComment block")
+		nPara.Length = len(nPara.Text)
+	}
+	if z := t.peek(1).Type; blank == 0 &&
+		z != itemCommentMark && z != itemEOF {
+		// A valid comment contains a blank line after the comment
+		// block
+		log.Debugln("Found warningExplicitMarkupWithUnIndent")
+		t.nodeTarget.append(newComment(nPara, &t.id))
+		return t.systemMessage(warningExplicitMarkupWithUnIndent)
+	}
+	log.Debugln("Found NodeComment")
+	return newComment(nPara, &t.id)
+}
+
+// commentContinues returns true if the next line continues the body of the
+// comment with the marker i. As in docutils, the body is indented relative to
+// the marker, so inside a block quote or list item, a line aligned with the
+// marker ends the comment.
+func (t *Tree) commentContinues(i *item) bool {
+	return t.peek(1).Type == itemSpace &&
+		t.peek(1).Length > int(i.StartPosition)-1 &&
+		t.peek(2).Type == itemParagraph
+}
+
+// indentMessages appends a system message to the current nodeTarget for each
+// line before line that contains non-ASCII whitespace in its indentation.
+func (t *Tree) indentMessages(line Line) {
+	for len(t.indentNotices) > 0 && t.indentNotices[0].line < line {
+		t.indentNotice = &t.indentNotices[0]
+		t.indentNotices = t.indentNotices[1:]
+		m := warningNonASCIIWhitespaceIndent
+		if t.nbspIndent && t.indentNotice.r == '\u00a0' {
+			m = infoNoBreakSpaceIndent
 		}
-		n = newComment(nPara, &t.id)
+		t.nodeTarget.append(t.systemMessage(m))
+		t.indentNotice = nil
 	}
-	return n
 }
 
 // systemMessage generates a Node based on the passed parserMessage. The
@@ -744,7 +1380,8 @@ func (t *Tree) systemMessage(err parserMessage) Node {
 	switch err {
 	case infoOverlineTooShortForTitle:
 		var inText string
-		if t.token[zed-2] != nil {
+		if t.token[zed-1].Type == itemTitle {
+			// The overline, the title, and the underline.
 			inText = t.token[zed-2].Text + "\n" +
 				t.token[zed-1].Text + "\n" + t.token[zed].Text
 			s.Line = t.token[zed-2].Line
@@ -817,16 +1454,58 @@ func (t *Tree) systemMessage(err parserMessage) Node {
 		s.Line = t.token[zed-1].Line
 	case warningExplicitMarkupWithUnIndent:
 		s.Line = t.token[zed+1].Line
+	case infoNoBreakSpaceIndent, warningNonASCIIWhitespaceIndent:
+		n := t.indentNotice
+		msg.Text += fmt.Sprintf(" Found %U at column %d.", n.r, n.column)
+		msg.Length = len(msg.Text)
+		lbText = n.text
+		lbTextLen = len(lbText)
+		s.Line = n.line
 	case errorInvalidSectionOrTransitionMarker:
-		lbText = t.token[zed-1].Text + "\n" + t.token[zed].Text
-		s.Line = t.token[zed-1].Line
+		lbText = t.token[zed].Text
+		if p := t.token[zed-1]; p != nil {
+			lbText = p.Text + "\n" + lbText
+			s.Line = p.Line
+		}
 		lbTextLen = len(lbText)
 	case severeIncompleteSectionTitle,
 		severeMissingMatchingUnderlineForOverline:
-		lbText = t.token[zed-2].Text + "\n" +
-			t.token[zed-1].Text + t.token[zed].Text
-		s.Line = t.token[zed-2].Line
+		// The lines from the overline to the current token.
+		s.Line = t.peekBackTo(itemSectionAdornment).Line
+		lbText = strings.Join(t.lex.lines[s.Line-1:t.token[zed].Line], "\n")
+		lbTextLen = len(lbText)
+	case infoInvisibleControl, warningInvisibleControlRemoved:
+		n := t.controlNotice
+		msg.Text += fmt.Sprintf(" Found %U at line %d, column %d.", n.r,
+			n.line, n.column)
+		msg.Length = len(msg.Text)
+		s.Line = n.line
+	case infoSectionBeyondMaxDepth, errorSectionBeyondMaxDepth:
+		s.Line = t.token[zed-1].Line
+	case errorUnknownDirectiveType:
+		d := t.directive
+		msg.Text = fmt.Sprintf("Unknown directive type %q.", d.name)
+		msg.Length = len(msg.Text)
+		lbText = d.text
+		lbTextLen = len(lbText)
+		s.Line = d.line
+	case errorDirectiveContentExpected:
+		d := t.directive
+		msg.Text = fmt.Sprintf("Content block expected for the %q "+
+			"directive; none found.", d.name)
+		msg.Length = len(msg.Text)
+		lbText = d.text
 		lbTextLen = len(lbText)
+		s.Line = d.line
+	case errorDirectiveInvalidOption:
+		d := t.directive
+		msg.Text = fmt.Sprintf("Error in %q directive: invalid option "+
+			"value: (option: %q; value: %q).", d.name,
+			d.invalid.name, d.invalid.value)
+		msg.Length = len(msg.Text)
+		lbText = d.text
+		lbTextLen = len(lbText)
+		s.Line = d.line
 	case severeUnexpectedSectionTitleOrTransition:
 		lbText = t.token[zed].Text
 		lbTextLen = len(lbText)
@@ -858,27 +1537,280 @@ func (t *Tree) systemMessage(err parserMessage) Node {
 	return s
 }
 
-var lastEnum *EnumListNode
+// isEnumListItem returns true if i begins an enumerated list item. The
+// enumerator of the item begins with an affix only if it is enclosed in
+// parentheses.
+func isEnumListItem(i *item) bool {
+	switch i.Type {
+	case itemEnumListArabic, itemEnumListAlpha, itemEnumListRoman,
+		itemEnumListAuto:
+		return true
+	}
+	return i.Type == itemEnumListAffix && i.Text == "("
+}
 
-func (t *Tree) enumList(i *item) (n Node) {
-	// FIXME: This function is COMPLETELY not final. It is only setup for
-	// passing section test TitleNumberedGood0100.
-	var eNode *EnumListNode
-	var affix *item
-	if lastEnum == nil {
+// enumListItem returns the enumerated list item begun by the enumerator i,
+// and sets the nodeTarget to the list of the item. As in docutils, the item
+// continues the open list at its column if its enumerator has the same format
+// and is an auto enumerator ("#") or the next one in the sequence of the list.
+// Explicit enumerators do not continue a list with auto enumerators. If the
+// enumerator is in the body of the open item, a nested list is begun in the
+// item, otherwise a new list is begun in the nodeTarget.
+func (t *Tree) enumListItem(i *item) Node {
+	col := int(i.StartPosition) - 1
+	for e := t.innerEnum(); e != nil && e.indent > col; e = t.innerEnum() {
+		t.closeEnum()
+	}
+	enum, affix := i, t.peek(1)
+	if i.Type == itemEnumListAffix {
+		// The enumerator is enclosed in parentheses
+		enum = t.peek(1)
+	}
+	e := t.innerEnum()
+	if e != nil && e.indent == col {
+		enum = enumInSequence(e.node, enum)
+	}
+	enType, afType := enumListFormat(enum, affix)
+	auto := enum.Type == itemEnumListAuto
+	ordinal := enumOrdinal(enum)
+	if e != nil && e.indent == col {
+		if auto {
+			ordinal = e.ordinal + 1
+		}
+		if e.node.Affix != afType || !auto && (e.node.EnumType != enType ||
+			e.auto || ordinal != e.ordinal+1) {
+			// A different enumerator begins a new list.
+			t.closeEnum()
+			t.enumListEnded()
+			e = nil
+		}
+	}
+	if e == nil || e.indent < col {
+		target := t.nodeTarget
+		if e != nil {
+			target = &e.item.NodeList
+		}
+		if auto {
+			ordinal = 1
+		}
+		e = &enumLevel{
+			node:   newEnumListNode(enum, affix, &t.id),
+			indent: col,
+			target: target,
+		}
+		target.append(e.node)
+		t.enums = append(t.enums, e)
+	}
+	e.ordinal = ordinal
+	e.auto = e.auto || auto
+	e.item = newEnumListItemNode(enum, ordinal, &t.id)
+	// The body of the item begins after the enumerator and the spaces that
+	// follow it. Lines continuing the item must be indented to this
+	// column.
+	e.body = col + i.Length + t.peek(1).Length
+	if i.Type == itemEnumListAffix {
+		e.body += t.peek(2).Length
+		t.next(2)
+	} else {
 		t.next(1)
-		affix = t.token[zed]
+	}
+	if t.peek(1).Type == itemSpace {
+		e.body += t.next(1).Length
+	}
+	t.nodeTarget = &e.node.NodeList
+	return e.item
+}
+
+// enumInSequence returns the enumerator i read in the sequence of the list l,
+// if it is valid in that sequence. As in docutils, a single letter that is a
+// roman numeral is alphabetic in an alphabetic list, such as the "i" following
+// "h", and roman in a roman list, such as the "v" following "iv".
+func enumInSequence(l *EnumListNode, i *item) *item {
+	seq := i.Type
+	switch {
+	case i.Type == itemEnumListRoman && len(i.Text) == 1 &&
+		(l.EnumType == enumListLowerAlpha ||
+			l.EnumType == enumListUpperAlpha):
+		seq = itemEnumListAlpha
+	case i.Type == itemEnumListAlpha && romanValue(i.Text) > 0 &&
+		(l.EnumType == enumListLowerRoman ||
+			l.EnumType == enumListUpperRoman):
+		seq = itemEnumListRoman
+	default:
+		return i
+	}
+	e := *i
+	e.Type = seq
+	return &e
+}
+
+// innerEnum returns the innermost open enumerated list, or nil if there is
+// none.
+func (t *Tree) innerEnum() *enumLevel {
+	if len(t.enums) == 0 {
+		return nil
+	}
+	return t.enums[len(t.enums)-1]
+}
+
+// closeEnum closes the innermost open enumerated list. The nodeTarget is set
+// to the NodeList containing the list.
+func (t *Tree) closeEnum() {
+	e := t.innerEnum()
+	t.enums = t.enums[:len(t.enums)-1]
+	t.nodeTarget = e.target
+}
+
+// enumListEnded adds a warningEnumListWithUnIndent system message to the
+// nodeTarget if the enumerated list that just ended is not followed by a blank
+// line.
+func (t *Tree) enumListEnded() {
+	if !t.followsBlankLine() {
+		t.nodeTarget.append(t.systemMessage(warningEnumListWithUnIndent))
+	}
+}
+
+// enumOrdinal returns the ordinal of the explicit enumerator i, such as 4 for
+// "4", "d", and "iv".
+func enumOrdinal(i *item) int {
+	switch i.Type {
+	case itemEnumListArabic:
+		n, _ := strconv.Atoi(i.Text)
+		return n
+	case itemEnumListAlpha:
+		return int(unicode.ToLower(rune(i.Text[0]))-'a') + 1
+	case itemEnumListRoman:
+		return romanValue(i.Text)
+	}
+	return 0
+}
+
+// fieldList adds the field begun by the field marker i to the open field
+// list, or begins a new list with the field. The new list is returned, nil is
+// returned if the field is added to the open list.
+func (t *Tree) fieldList(i *item) (n Node) {
+	name := t.next(1)
+	// The colon ending the field marker
+	t.next(1)
+	if t.openFieldList == nil {
+		t.openFieldList = newFieldList(i, &t.id)
+		n = t.openFieldList
+	}
+	t.openField = newField(name, &t.id)
+	t.openFieldList.NodeList.append(t.openField)
+	if t.peek(1).Type == itemSpace {
 		t.next(1)
-		eNode = newEnumListNode(i, affix, &t.id)
+	}
+	if t.peek(1).Type == itemFieldBody {
+		t.fieldBody(t.next(1))
+	}
+	return
+}
+
+// fieldBody adds a paragraph beginning with the field body line i to the body
+// of the open field. The body lines directly following i continue the
+// paragraph.
+func (t *Tree) fieldBody(i *item) {
+	p := &item{
+		Text:          i.Text,
+		Line:          i.Line,
+		StartPosition: i.StartPosition,
+	}
+	for t.peek(1).Type == itemSpace && t.peek(2).Type == itemFieldBody {
+		p.Text += "\n" + t.next(2).Text
+	}
+	p.Length = utf8.RuneCountInString(p.Text)
+	t.openField.Body.NodeList.append(newParagraph(p, &t.id))
+}
+
+// closeFieldList ends the open field list. If the list is followed by an
+// unindented line instead of a blank line, a warningFieldListWithUnIndent
+// system message is added after the list.
+func (t *Tree) closeFieldList() {
+	t.openFieldList = nil
+	t.openField = nil
+	if t.peekBack(1) != nil && t.peekBack(1).Type != itemBlankLine &&
+		t.token[zed].Type != itemSpace {
+		t.nodeTarget.append(t.systemMessage(warningFieldListWithUnIndent))
+	}
+}
+
+// optionList adds the item begun by the option i to the open option list, or
+// begins a new list with the item. The new list is returned, nil is returned
+// if the item is added to the open list.
+func (t *Tree) optionList(i *item) (n Node) {
+	if t.openOptionList == nil {
+		t.openOptionList = newOptionList(i, &t.id)
+		n = t.openOptionList
+	}
+	t.openOptionListItem = newOptionListItem(i, &t.id)
+	t.openOptionList.NodeList.append(t.openOptionListItem)
+	for {
+		o := newOption(i, &t.id)
+		var delim string
+		switch {
+		case t.peek(1).Type == itemOptionList && t.peek(1).Text == "=",
+			t.peek(1).Type == itemSpace &&
+				t.peek(2).Type == itemOptionArgument:
+			delim = t.next(1).Text
+			fallthrough
+		case t.peek(1).Type == itemOptionArgument:
+			a := t.next(1)
+			t.id++
+			o.Argument = &OptionArgumentNode{
+				ID:            ID(t.id),
+				Type:          NodeOptionArgument,
+				Text:          a.Text,
+				Length:        a.Length,
+				StartPosition: a.StartPosition,
+				Line:          a.Line,
+				Delimiter:     delim,
+			}
+		}
+		t.openOptionListItem.NodeList.append(o)
+		if t.peek(1).Type != itemOptionList {
+			break
+		}
+		// The comma and the space separating the options
+		t.next(2)
+		i = t.next(1)
+	}
+	if t.peek(1).Type == itemSpace {
 		t.next(1)
-		eNode.NodeList.append(newParagraph(t.token[zed], &t.id))
-	} else {
-		t.next(3)
-		lastEnum.NodeList.append(newParagraph(t.token[zed], &t.id))
-		return nil
 	}
-	lastEnum = eNode
-	return eNode
+	if t.peek(1).Type == itemOptionDescription {
+		t.optionDescription(t.next(1))
+	}
+	return
+}
+
+// optionDescription adds a paragraph beginning with the description line i
+// to the description of the open option list item. The description lines
+// directly following i continue the paragraph.
+func (t *Tree) optionDescription(i *item) {
+	p := &item{
+		Text:          i.Text,
+		Line:          i.Line,
+		StartPosition: i.StartPosition,
+	}
+	for t.peek(1).Type == itemSpace &&
+		t.peek(2).Type == itemOptionDescription {
+		p.Text += "\n" + t.next(2).Text
+	}
+	p.Length = utf8.RuneCountInString(p.Text)
+	t.openOptionListItem.Description.NodeList.append(newParagraph(p, &t.id))
+}
+
+// closeOptionList ends the open option list. If the list is followed by an
+// unindented line instead of a blank line, a warningOptionListWithUnIndent
+// system message is added after the list.
+func (t *Tree) closeOptionList() {
+	t.openOptionList = nil
+	t.openOptionListItem = nil
+	if t.peekBack(1) != nil && t.peekBack(1).Type != itemBlankLine &&
+		t.token[zed].Type != itemSpace {
+		t.nodeTarget.append(t.systemMessage(warningOptionListWithUnIndent))
+	}
 }
 
 func (t *Tree) paragraph(i *item) Node {
@@ -894,15 +1826,62 @@ func (t *Tree) paragraph(i *item) Node {
 	// lines, then add a newline between the previous paragraph and the
 	// current.
 	for {
+		if b := t.innerBullet(); b != nil &&
+			t.peek(1).Type == itemSpace && t.peek(1).Length == b.body &&
+			t.peek(2).Type == itemParagraph {
+			// The line is aligned with the bullet list item body,
+			// so it continues the paragraph.
+			t.next(1)
+		} else if len(t.definitions) > 0 &&
+			t.peek(1).Type == itemSpace &&
+			t.peek(1).Length == int(i.StartPosition)-1 &&
+			t.peek(2).Type == itemParagraph {
+			// The line is aligned with the definition, so it
+			// continues the paragraph.
+			t.next(1)
+		} else if q := t.innerQuote(); q != nil &&
+			t.peek(1).Type == itemSpace && t.peek(1).Length == q.indent &&
+			t.peek(2).Type == itemParagraph {
+			// The line is aligned with the block quote, so it
+			// continues the paragraph.
+			t.next(1)
+		}
 		nItem := t.next(1)
 		if nItem.Type != itemParagraph {
 			t.backup()
 			break
 		}
+		if b := t.innerBullet(); b != nil &&
+			int(nItem.StartPosition) <= b.body {
+			// The line is unindented from the bullet list item
+			// body, so it is not part of the item.
+			t.backup()
+			break
+		}
+		if t.indentLevel > 0 && nItem.StartPosition < i.StartPosition {
+			// The line is unindented from the indented paragraph,
+			// so it ends the enclosing element.
+			t.backup()
+			break
+		}
 		npItem.Text += "\n" + nItem.Text
 	}
 
-	npItem.Length = len(npItem.Text)
+	if text, ok := literalMarker(npItem.Text); ok {
+		npItem.Text = text
+		t.literalExpected = true
+		if text == "" {
+			// As in docutils, a paragraph of only "::" is removed.
+			return nil
+		}
+	}
+
+	t.controlNotice = t.findControl(npItem.Line, npItem.Text)
+	if t.controlNotice != nil && t.rejectControls {
+		npItem.Text = removeControls(npItem.Text)
+	}
+
+	npItem.Length = utf8.RuneCountInString(npItem.Text)
 
 	sec := newParagraph(npItem, &t.id)
 
@@ -910,57 +1889,295 @@ func (t *Tree) paragraph(i *item) Node {
 	return sec
 }
 
+// literalMarker returns text without the literal block marker ending it, as
+// docutils displays the paragraph: "text::" becomes "text:", and a marker
+// separated from the text by whitespace is removed, so "text ::" becomes
+// "text" and "::" becomes empty. ok is false if text does not end with an
+// unescaped marker.
+func literalMarker(text string) (s string, ok bool) {
+	if !hasLiteralMarker(text) {
+		return text, false
+	}
+	text = strings.TrimSuffix(text, "::")
+	if trimmed := strings.TrimRight(text, " \n"); trimmed != text ||
+		text == "" {
+		return trimmed, true
+	}
+	return text + ":", true
+}
+
+// literalBlock adds the literal block whose first line follows the current
+// itemSpace, which is the indentation common to the lines of the block. The
+// lines are joined with the blank lines between them. A block that ends
+// without a blank line is followed by a warningLiteralBlockWithUnIndent
+// system message.
+func (t *Tree) literalBlock() {
+	target := t.nodeTarget
+	first := t.next(1)
+	lb := &item{
+		Text:          first.Text,
+		Line:          first.Line,
+		StartPosition: first.StartPosition,
+	}
+	blank := 0
+	for {
+		if t.peek(1).Type == itemBlankLine {
+			t.next(1)
+			blank++
+		} else if t.peek(1).Type == itemSpace &&
+			t.peek(2).Type == itemLiteralBlock {
+			t.next(1)
+			lb.Text += strings.Repeat("\n", blank+1) + t.next(1).Text
+			blank = 0
+		} else {
+			break
+		}
+	}
+	lb.Length = utf8.RuneCountInString(lb.Text)
+	target.append(newLiteralBlock(lb, &t.id))
+	if blank == 0 && t.peek(1).Type != itemEOF {
+		m := t.systemMessage(warningLiteralBlockWithUnIndent)
+		m.(*SystemMessageNode).Line = t.peek(1).Line
+		target.append(m)
+	}
+}
+
+// doctestBlock returns the doctest block whose first line is i. The
+// following lines are joined to the first, without the indentation of the
+// first line.
+func (t *Tree) doctestBlock(i *item) Node {
+	db := &item{
+		Text:          i.Text,
+		Line:          i.Line,
+		StartPosition: i.StartPosition,
+	}
+	for {
+		if t.peek(1).Type == itemSpace &&
+			t.peek(2).Type == itemDoctestBlock {
+			t.next(1)
+		} else if t.peek(1).Type != itemDoctestBlock {
+			break
+		}
+		db.Text += "\n" + t.next(1).Text
+	}
+	db.Length = utf8.RuneCountInString(db.Text)
+	return newDoctestBlock(db, &t.id)
+}
+
+// quotedLiteralBlock adds the quoted literal block whose first line is i. The
+// lines of a quoted literal block are not indented and keep their quote
+// characters. A block that ends without a blank line is followed by an
+// errorInconsistentLiteralBlockQuoting system message, as the line that ends
+// it does not begin with the quote character.
+func (t *Tree) quotedLiteralBlock(i *item) {
+	target := t.nodeTarget
+	lb := &item{
+		Text:          i.Text,
+		Line:          i.Line,
+		StartPosition: i.StartPosition,
+	}
+	for t.peek(1).Type == itemLiteralBlock {
+		lb.Text += "\n" + t.next(1).Text
+	}
+	lb.Length = utf8.RuneCountInString(lb.Text)
+	target.append(newLiteralBlock(lb, &t.id))
+	if p := t.peek(1); p.Type != itemBlankLine && p.Type != itemEOF {
+		m := t.systemMessage(errorInconsistentLiteralBlockQuoting)
+		m.(*SystemMessageNode).Line = p.Line
+		target.append(m)
+	}
+}
+
+// blockquote handles the indented lines of a block quote. Block quotes are
+// nested by successive indentation levels; a line indented more than the
+// innermost open block quote opens new block quotes, and a line indented less
+// closes the block quotes indented beyond it. If i is an itemSpace, the block
+// quotes are opened for the next item and nil is returned. Otherwise i is the
+// first line of a paragraph in the innermost block quote.
 func (t *Tree) blockquote(i *item) Node {
 	log.Debugln("START")
 	log.Debugln("Got type", i.Type)
 
-	s := i
-	if i.Type != itemSpace {
-		// If i is not itemSpace, it is a itemBlockQuote. In that case
-		// we will get the last itemSpace token found to use for the
-		// indent level calculation.
-		s = t.peekBackTo(itemSpace)
+	if i.Type == itemSpace {
+		if p := t.peek(1).Type; p == itemBlockQuote ||
+			p == itemAttribution {
+			log.Debugln("Next item is", p)
+			return nil
+		}
+		t.openQuotes(i.Length, i.Line, 0)
+		return nil
+	}
+
+	// If i is not itemSpace, it is a itemBlockQuote. In that case we will
+	// get the last itemSpace token found to use for the indent
+	// calculation.
+	indent := t.peekBackTo(itemSpace).Length
+	for len(t.quotes) > 0 && t.innerQuote().indent > indent {
+		t.closeQuote()
 	}
-	level := s.Length / t.indentWidth
+	if q := t.innerQuote(); q != nil && q.attributed {
+		// Text following an attribution begins a new block quote.
+		t.closeQuote()
+	}
+	t.openQuotes(indent, i.Line, i.StartPosition)
 
-	log.Debugf("t.indentLevel == level :: %d == %d\n", t.indentLevel, level)
-	if t.indentLevel == level {
-		i.Type = itemParagraph
-		return newParagraph(i, &t.id)
+	i.Type = itemParagraph
+	log.Debugln("END")
+	return t.paragraph(i)
+}
+
+// innerQuote returns the innermost open block quote, or nil if there is none.
+func (t *Tree) innerQuote() *quoteIndent {
+	if len(t.quotes) == 0 {
+		return nil
 	}
+	return t.quotes[len(t.quotes)-1]
+}
 
-	if i.Type == itemSpace {
-		if t.peek(1).Type != itemBlockQuote {
-			t.indentLevel = level
-			return newBlockQuote(
-				&item{Type: itemBlockQuote, Line: i.Line},
-				level, &t.id)
+// closeQuote closes the innermost open block quote. The nodeTarget is set to
+// the enclosing block quote, or to the NodeList containing the outermost block
+// quote.
+func (t *Tree) closeQuote() {
+	t.quotes = t.quotes[:len(t.quotes)-1]
+	t.indentLevel = len(t.quotes)
+	if q := t.innerQuote(); q != nil {
+		t.nodeTarget = &q.node.NodeList
+	} else {
+		t.nodeTarget = t.quoteTarget
+	}
+}
+
+// openQuotes opens block quotes until the innermost open block quote has an
+// indent of indent, and sets the nodeTarget to the innermost block quote. The
+// indent of each new block quote is the least indentation found in the lines
+// of the block quote, beginning at line, so uneven indents produce the same
+// nesting as docutils. pos is the StartPosition of the text at indent, if it
+// is known.
+func (t *Tree) openQuotes(indent int, line Line, pos StartPosition) {
+	for {
+		base := 0
+		target := t.nodeTarget
+		if len(t.quotes) == 0 {
+			t.quoteTarget = t.nodeTarget
 		}
-		log.Debugln("Next item is itemBlockQuote")
-		return nil
+		if q := t.innerQuote(); q != nil {
+			if q.indent >= indent {
+				break
+			}
+			base = q.indent
+			target = &q.node.NodeList
+		}
+		qIndent := t.quoteIndent(line, base)
+		if qIndent <= base || qIndent > indent {
+			qIndent = indent
+		}
+		bqItem := &item{Type: itemBlockQuote, Line: line,
+			StartPosition: pos}
+		if qIndent != indent && pos != 0 {
+			bqItem.StartPosition = StartPosition(qIndent + 1)
+		}
+		bq := newBlockQuote(bqItem, len(t.quotes)+1, &t.id)
+		target.append(bq)
+		t.quotes = append(t.quotes, &quoteIndent{node: bq, indent: qIndent})
 	}
+	t.indentLevel = len(t.quotes)
+	t.nodeTarget = &t.innerQuote().node.NodeList
+}
 
-	levelChanged := false
-	if t.indentLevel != level {
-		log.Debugln("Setting indentLevel to ", level)
-		t.indentLevel = level
-		levelChanged = true
+// quoteIndent returns the least indentation of the lines of input beginning
+// at line that are indented more than base. Blank lines are skipped and the
+// first line indented by base or less ends the search.
+func (t *Tree) quoteIndent(line Line, base int) int {
+	indent := -1
+	for _, l := range t.lex.lines[line-1:] {
+		if strings.TrimSpace(l) == "" {
+			continue
+		}
+		w := t.lineIndent(l)
+		if w <= base {
+			break
+		}
+		if indent == -1 || w < indent {
+			indent = w
+		}
 	}
+	return indent
+}
 
-	var sec Node
-	n := *i
+// lineIndent returns the width of the indentation of line in runes, using the
+// same policy as the lexer.
+func (t *Tree) lineIndent(line string) (w int) {
+	for _, r := range line {
+		if !isSpace(r) && !(t.nbspIndent && r == '\u00a0') {
+			break
+		}
+		w++
+	}
+	return
+}
 
-	if levelChanged {
-		// FIXME: Code a token ring insertion API
-		t.token[zed+1] = &n
-		t.indentLevel = level
-		sec = newBlockQuote(&item{Type: itemBlockQuote, Line: i.Line,
-			StartPosition: i.StartPosition, Length: i.Length}, level,
-			&t.id)
+// attribution returns an AttributionNode from the itemAttribution i and the
+// itemAttribution lines continuing it. The attribution marker and the
+// whitespace following it are not part of the attribution text. docutils
+// only finds attributions in block quotes; elsewhere, and if the innermost
+// block quote already has an attribution, the lines are paragraph text, or a
+// definition term and its definition. nil is returned for a definition term,
+// which is parsed by the next iteration of the parse loop.
+func (t *Tree) attribution(i *item) Node {
+	indent := t.peekBackTo(itemSpace).Length
+	for len(t.quotes) > 0 && t.innerQuote().indent > indent {
+		t.closeQuote()
+	}
+	if q := t.innerQuote(); q == nil || q.indent != indent || q.attributed {
+		if s := t.peek(1); q == nil && s.Type == itemSpace &&
+			s.Length > indent && t.peek(2).Type == itemAttribution {
+			// Indented lines directly below the text make it a
+			// definition term.
+			i.Type = itemDefinitionTerm
+		}
+		if t.peek(1).Type == itemSpace &&
+			t.peek(2).Type == itemAttribution {
+			t.attributionText(t.peek(2))
+		}
+		switch {
+		case i.Type == itemDefinitionTerm:
+			t.backup()
+			return nil
+		case q == nil:
+			i.Type = itemParagraph
+			return t.paragraph(i)
+		}
+		i.Type = itemBlockQuote
+		return t.blockquote(i)
 	}
+	q := t.innerQuote()
+	q.attributed = true
+	t.nodeTarget = &q.node.NodeList
+	mLen := attributionMarker(i.Text)
+	aItem := &item{
+		Text:          i.Text[mLen:],
+		Line:          i.Line,
+		StartPosition: i.StartPosition + StartPosition(mLen),
+	}
+	for t.peek(1).Type == itemSpace && t.peek(2).Type == itemAttribution {
+		t.next(2)
+		aItem.Text += "\n" + t.token[zed].Text
+	}
+	aItem.Length = len(aItem.Text)
+	return newAttribution(aItem, &t.id)
+}
 
-	log.Debugln("END")
-	return sec
+// attributionText retypes the attribution line a, which is not an
+// attribution, as a paragraph line. The attribution lines continuing a are
+// removed from the token buffer one at a time and joined to it, so the buffer
+// is never read beyond the line after a.
+func (t *Tree) attributionText(a *item) {
+	a.Type = itemParagraph
+	for t.peek(3).Type == itemSpace && t.peek(4).Type == itemAttribution {
+		a.Text += "\n" + t.peek(4).Text
+		t.dropTokens(3, 2)
+	}
+	a.Length = utf8.RuneCountInString(a.Text)
 }
 
 func (t *Tree) definitionList(i *item) Node {
@@ -976,14 +2193,211 @@ func (t *Tree) definitionList(i *item) Node {
 
 func (t *Tree) definitionListItem(i *item) Node {
 	// TODO: Check for proper indentation!
+	var classifiers []*item
+	for t.peek(1).Type == itemClassifierDelimiter {
+		t.next(2)
+		classifiers = append(classifiers, t.token[zed])
+	}
 	def := t.peek(2)
-	return newDefinitionListItem(i, def, &t.id)
+	return newDefinitionListItem(i, classifiers, def, &t.id)
+}
+
+// bulletListItem returns the bullet list item begun by the bullet i, and sets
+// the nodeTarget to the list of the item. The item is added to the open list
+// with the same bullet at the same column. If the bullet is in the body of the
+// open item, a nested list is begun in the item, otherwise a new list is begun
+// in the nodeTarget.
+func (t *Tree) bulletListItem(i *item) Node {
+	col := int(i.StartPosition) - 1
+	for b := t.innerBullet(); b != nil && b.indent > col; b = t.innerBullet() {
+		t.closeBullet()
+	}
+	b := t.innerBullet()
+	if b != nil && b.indent == col && b.node.Bullet != i.Text {
+		// A different bullet character begins a new list.
+		t.closeBullet()
+		t.bulletListEnded()
+		b = nil
+	}
+	if b == nil || b.indent < col {
+		target := t.nodeTarget
+		if b != nil {
+			target = &b.item.NodeList
+		}
+		b = &bulletLevel{
+			node:   newBulletListNode(i, &t.id),
+			indent: col,
+			target: target,
+		}
+		target.append(b.node)
+		t.bullets = append(t.bullets, b)
+	}
+	// The body of the item begins after the bullet and the spaces that
+	// follow it. Lines continuing the item must be indented to this
+	// column.
+	b.body = col + i.Length
+	if s := t.peek(1); s != nil && s.Type == itemSpace {
+		b.body += s.Length
+	}
+	b.item = newBulletListItemNode(i, &t.id)
+	t.nodeTarget = &b.node.NodeList
+	return b.item
 }
 
-func (t *Tree) bulletList(i *item) Node {
-	return newBulletListNode(i, &t.id)
+// innerBullet returns the innermost open bullet list, or nil if there is none.
+func (t *Tree) innerBullet() *bulletLevel {
+	if len(t.bullets) == 0 {
+		return nil
+	}
+	return t.bullets[len(t.bullets)-1]
 }
 
-func (t *Tree) bulletListItem(i *item) Node {
-	return newBulletListItemNode(i, &t.id)
+// closeBullet closes the innermost open bullet list. The nodeTarget is set to
+// the NodeList containing the list.
+func (t *Tree) closeBullet() {
+	b := t.innerBullet()
+	t.bullets = t.bullets[:len(t.bullets)-1]
+	t.nodeTarget = b.target
+}
+
+// closeIndented closes the lists that the line beginning with i is not part
+// of, because it is indented less than their content: the nested definition
+// lists, and the bullet and enumerated lists whose item body the line is not
+// aligned with. A bullet or an enumerator at the column of an open list
+// continues that list. If every bullet list is closed by a line that is
+// indented, the line begins a block quote.
+func (t *Tree) closeIndented(i *item) {
+	col, next := 0, i
+	if i.Type == itemSpace {
+		col, next = i.Length, t.peek(1)
+	}
+	var ended *definitionLevel
+	for len(t.definitions) > 0 {
+		d := t.innerDefinition()
+		if d.indent == 0 || col > d.indent ||
+			col == d.indent && next.Type == itemDefinitionTerm {
+			break
+		}
+		t.definitions = t.definitions[:len(t.definitions)-1]
+		ended = d
+	}
+	if ended != nil {
+		t.nodeTarget = ended.target
+		if !t.followsBlankLine() {
+			m := t.systemMessage(warningDefinitionListWithUnIndent)
+			t.nodeTarget.append(m)
+		}
+	}
+	enumClosed := false
+	for e := t.innerEnum(); e != nil && col < e.body; e = t.innerEnum() {
+		if isEnumListItem(next) && col >= e.indent {
+			break
+		}
+		t.closeEnum()
+		enumClosed = true
+	}
+	if enumClosed {
+		t.enumListEnded()
+	}
+	closed := false
+	for b := t.innerBullet(); b != nil && col < b.body; b = t.innerBullet() {
+		if next.Type == itemBullet && col >= b.indent {
+			break
+		}
+		t.closeBullet()
+		closed = true
+	}
+	if !closed {
+		return
+	}
+	t.bulletListEnded()
+	if q := t.innerQuote(); len(t.bullets) == 0 &&
+		(q == nil && col > 0 || q != nil && col > q.indent) {
+		t.openQuotes(col, i.Line, 0)
+	}
+}
+
+// bulletListEnded adds a warningBulletListWithUnIndent system message to the
+// nodeTarget if the bullet list that just ended is not followed by a blank
+// line.
+func (t *Tree) bulletListEnded() {
+	if !t.followsBlankLine() {
+		t.nodeTarget.append(t.systemMessage(warningBulletListWithUnIndent))
+	}
+}
+
+// followsBlankLine reports whether the line of the current token follows a
+// blank line, or is the first line of the input.
+func (t *Tree) followsBlankLine() bool {
+	p := t.peekBack(1)
+	if p != nil && p.Type == itemSpace && p.StartPosition == 1 {
+		p = t.peekBack(2)
+	}
+	return p == nil || p.Type == itemBlankLine
+}
+
+// sectionTarget returns the NodeList that unindented nodes are appended to,
+// which is the NodeList of the last section, or the root of the tree if there
+// are no sections. Sections flattened by WithMaxSectionDepth are not in the
+// tree, their nodes are appended to the last section at the maximum depth.
+func (t *Tree) sectionTarget() *NodeList {
+	sec := t.sectionLevels.lastSectionNode
+	if sec == nil {
+		return &t.Nodes
+	}
+	if t.maxSectionDepth > 0 && sec.Level > t.maxSectionDepth &&
+		t.depthMode == DepthFlatten {
+		sec = t.sectionLevels.LastSectionByLevel(t.maxSectionDepth)
+	}
+	return &sec.NodeList
+}
+
+// closeBulletList ends the open bullet lists. If the lists are not followed
+// by a blank line, a warningBulletListWithUnIndent system message is added
+// after the outermost list.
+func (t *Tree) closeBulletList() {
+	if len(t.bullets) == 0 {
+		return
+	}
+	target := t.bullets[0].target
+	t.bullets = nil
+	if !t.followsBlankLine() {
+		target.append(t.systemMessage(warningBulletListWithUnIndent))
+	}
+}
+
+// innerDefinition returns the innermost open definition list, or nil if there
+// is none.
+func (t *Tree) innerDefinition() *definitionLevel {
+	if len(t.definitions) == 0 {
+		return nil
+	}
+	return t.definitions[len(t.definitions)-1]
+}
+
+// closeDefinitionList ends the open definition lists. If the lists are not
+// followed by a blank line, a warningDefinitionListWithUnIndent system message
+// is added after the outermost list.
+func (t *Tree) closeDefinitionList() {
+	if len(t.definitions) == 0 {
+		return
+	}
+	target := t.definitions[0].target
+	t.definitions = nil
+	if !t.followsBlankLine() {
+		m := t.systemMessage(warningDefinitionListWithUnIndent)
+		target.append(m)
+	}
+}
+
+// closeBlockQuote ends the open block quotes. If the block quote is not
+// followed by a blank line, a warningBlockQuoteWithUnIndent system message is
+// added after the outermost block quote.
+func (t *Tree) closeBlockQuote() {
+	target := t.quoteTarget
+	t.quotes = nil
+	if t.peekBack(1) != nil && t.peekBack(1).Type != itemBlankLine {
+		m := t.systemMessage(warningBlockQuoteWithUnIndent)
+		target.append(m)
+	}
 }
@@ -0,0 +1,223 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+// To enable debug output when testing, use "go test -debug"
+
+package parse
+
+import "testing"
+
+func TestParseBlockQuoteNoBlankLineBad0000(t *testing.T) {
+	// A block quote with a three space indent followed by unindented text
+	// without a blank line ends with a warning.
+	testPath := testPathFromName("00.00-no-blank-line")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseBlockQuoteNoBlankLineInSectionBad0001(t *testing.T) {
+	// The warning for a block quote in a section is added to the section.
+	testPath := testPathFromName("00.01-no-blank-line-in-section")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseBlockQuoteUnexpectedIndentBad0100(t *testing.T) {
+	// Text indented from a paragraph of two lines, without a blank line,
+	// is a block quote following an "Unexpected indentation." error.
+	testPath := testPathFromName("01.00-unexpected-indent")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseBlockQuoteUnexpectedIndentInBlockQuoteBad0101(t *testing.T) {
+	// Text indented further in a block quote, without a blank line, is a
+	// nested block quote following the error.
+	testPath := testPathFromName("01.01-unexpected-indent-in-block-quote")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseBlockQuoteNBSPIndentText0000(t *testing.T) {
+	// By default a line indented with no-break spaces is an ordinary
+	// paragraph, and a warning names the character.
+	testPath := testPathFromName("00.00-nbsp-indent-text")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseBlockQuoteNBSPIndentLenient0001(t *testing.T) {
+	// With WithNBSPIndent, the same input is a block quote and an info
+	// message notes the no-break spaces.
+	testPath := testPathFromName("00.01-nbsp-indent-lenient")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test, WithNBSPIndent())
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseBlockQuoteTwoLevelsGood0100(t *testing.T) {
+	// Each additional level of indentation nests a block quote.
+	testPath := testPathFromName("01.00-two-levels")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseBlockQuoteThreeLevelsAttributionGood0101(t *testing.T) {
+	// An attribution ends only the innermost block quote, and returning
+	// to a previous level closes the inner block quotes.
+	testPath := testPathFromName("01.01-three-levels-attribution")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseBlockQuoteUnicodeEmDashGood0200(t *testing.T) {
+	// Attributions beginning with an em dash (U+2014) and with "---"
+	testPath := testPathFromName("02.00-unicode-em-dash")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseBlockQuoteUnevenIndentsGood0300(t *testing.T) {
+	// The outer block quote is indented by the least indent of its lines,
+	// so the eight space text is a nested block quote.
+	testPath := testPathFromName("03.00-uneven-indents")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseBlockQuoteAttributionGood0400(t *testing.T) {
+	// Block quotes ending with attributions
+	testPath := testPathFromName("04.00-para-bq-attrib")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseBlockQuoteTwoLineAttributionGood0401(t *testing.T) {
+	// Attributions continued on a consistently indented second line
+	testPath := testPathFromName("04.01-para-bq-two-line-attrib")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseBlockQuoteAttributionNoSpaceGood0402(t *testing.T) {
+	// Text following an attribution begins a new block quote, and the
+	// space after the attribution marker is optional.
+	testPath := testPathFromName("04.02-para-bq-attrib-no-space")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseBlockQuoteOneAttributionGood0403(t *testing.T) {
+	// Two block quotes separated by an attribution
+	testPath := testPathFromName("04.03-para-bq-one-attrib")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseBlockQuoteInvalidAttributionGood0404(t *testing.T) {
+	// Text beginning with "--" is a paragraph if it begins the block
+	// quote, is escaped, or the lines continuing it are indented
+	// unevenly.
+	testPath := testPathFromName("04.04-para-bq-attrib-invalid")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseBlockQuoteInvalidAfterAttributionGood0405(t *testing.T) {
+	// Text following an attribution begins a new block quote, so it is
+	// not an attribution itself.
+	testPath := testPathFromName("04.05-para-bq-attrib-with-invalid-attrib")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseBlockQuoteOnlyAttributionGood0406(t *testing.T) {
+	// Text beginning with "--" that is the only content of a block
+	// quote is a paragraph, not an attribution.
+	testPath := testPathFromName("04.06-para-bq-only-attrib")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseBlockQuoteAttributionOutsideBlockQuoteGood0407(t *testing.T) {
+	// Text beginning with "--" outside of a block quote is a paragraph,
+	// or a definition term if the next line is indented more.
+	testPath := testPathFromName("04.07-para-bq-attrib-outside-bq")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseBlockQuoteAttributionTermLinesGood0408(t *testing.T) {
+	// Text beginning with "--" in a definition, followed by two lines indented
+	// more, is a definition term and its definition.
+	testPath := testPathFromName("04.08-para-bq-attrib-term-lines")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseBlockQuoteSectionParagraphGood0500(t *testing.T) {
+	// A paragraph following a block quote in a section
+	testPath := testPathFromName("05.00-section-bq-paragraph")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseBlockQuoteFirstLineGood0600(t *testing.T) {
+	// An indented first line begins a block quote, as a line following
+	// a blank line does.
+	testPath := testPathFromName("06.00-bq-first-line")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseBlockQuoteFirstLineBulletGood0601(t *testing.T) {
+	// An indented bullet on the first line begins a list in a block
+	// quote.
+	testPath := testPathFromName("06.01-bq-first-line-bullet")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
@@ -0,0 +1,124 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+// To enable debug output when testing, use "go test -debug"
+
+package parse
+
+import "testing"
+
+func TestParseBulletListDifferentBulletsGood0000(t *testing.T) {
+	// A different bullet begins a new list. Lines aligned with the item
+	// body continue the item paragraph.
+	testPath := testPathFromName("00.00-different-bullets")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseBulletListItemStartsWithEmphasisGood0001(t *testing.T) {
+	// Only the bullet rune and the following space are inspected, so inline
+	// markup may begin the item body.
+	testPath := testPathFromName("00.01-item-starts-with-emphasis")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseBulletListItemStartsWithEscapeGood0002(t *testing.T) {
+	// An escaped asterisk beginning the item body is kept for the inline
+	// parser.
+	testPath := testPathFromName("00.02-item-starts-with-escape")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseBulletListItemStartsWithFlagLiteralGood0003(t *testing.T) {
+	// An inline literal option name beginning a two line item body.
+	testPath := testPathFromName("00.03-item-starts-with-flag-literal")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseBulletListItemOnlyInlineLiteralGood0004(t *testing.T) {
+	// An item body containing only an inline literal.
+	testPath := testPathFromName("00.04-item-only-inline-literal")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseBulletListItemOnlyFootnoteReferenceGood0005(t *testing.T) {
+	// An item body containing only a footnote reference.
+	testPath := testPathFromName("00.05-item-only-footnote-reference")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseBulletListEmphasisStartNotABulletGood0006(t *testing.T) {
+	// An asterisk followed by text is not a bullet.
+	testPath := testPathFromName("00.06-emphasis-start-not-a-bullet")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseBulletListNestedItemsGood0102(t *testing.T) {
+	// Items indented to the body of a parent item begin a bullet list
+	// nested in the parent item.
+	testPath := testPathFromName("01.02-nested-items")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseBulletListDefinitionListInItemGood0103(t *testing.T) {
+	// A definition list in the body of an item ends at the next line
+	// aligned with the item body.
+	testPath := testPathFromName("01.03-definition-list-in-item")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseBulletListNestedListsEndGood0104(t *testing.T) {
+	// A bullet at the column of an enclosing list ends the lists nested
+	// in its item.
+	testPath := testPathFromName("01.04-nested-lists-end")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseBulletListMixedBulletsMisalignedBodyBad0000(t *testing.T) {
+	// A change of bullet without a blank line and an item whose second
+	// line is off by one column both end the list with a warning.
+	testPath := testPathFromName("00.00-mixed-bullets-misaligned-body")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseBulletListParagraphAfterListBad0001(t *testing.T) {
+	// A paragraph following a bullet list without a blank line
+	testPath := testPathFromName("00.01-paragraph-after-list-no-blank-line")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
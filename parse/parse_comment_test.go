@@ -63,6 +63,53 @@ func TestParseTwoCommentsNoBlankLineBad0001(t *testing.T) {
 	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
 }
 
+func TestParseCommentAlignedLineInBlockQuoteBad0100(t *testing.T) {
+	// A line aligned with the comment marker in a block quote ends the
+	// comment with a warning.
+	testPath := testPathFromName("01.00-comment-aligned-line-in-block-quote")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseCommentAlignedLineInDefinitionBad0101(t *testing.T) {
+	// A line aligned with the comment marker in a definition ends the
+	// comment block with a warning.
+	testPath := testPathFromName("01.01-comment-aligned-line-in-definition")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseEmptyCommentInBulletItemBad0102(t *testing.T) {
+	// An empty comment in a list item directly followed by a nested list
+	testPath := testPathFromName("01.02-empty-comment-in-bullet-item")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseEmptyCommentInBlockQuoteBad0103(t *testing.T) {
+	// An empty comment in a block quote directly followed by a list
+	testPath := testPathFromName("01.03-empty-comment-in-block-quote")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseCommentBodyNoBlankLineBad0200(t *testing.T) {
+	// A comment body ending without a blank line before a paragraph.
+	testPath := testPathFromName("02.00-comment-body-no-blankline")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
 func TestParseCommentWithLiteralMarkGood0002(t *testing.T) {
 	// A comment ending with a literal block mark.
 	testPath := testPathFromName("00.02-comment-with-literal-mark")
@@ -161,3 +208,70 @@ func TestParseCommentTrailingBulletGood0502(t *testing.T) {
 	eNodes := test.expectNodes()
 	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
 }
+
+func TestParseCommentInBlockQuoteGood0600(t *testing.T) {
+	// The body of a comment in a block quote is indented relative to the
+	// comment marker.
+	testPath := testPathFromName("06.00-comment-in-block-quote")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseCommentInBulletItemGood0601(t *testing.T) {
+	// The body of a comment in a bullet list item is indented relative to
+	// the comment marker.
+	testPath := testPathFromName("06.01-comment-in-bullet-item")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseCommentLicenseFrontMatterGood0700(t *testing.T) {
+	// WithFrontMatterComment does not change the parse tree.
+	testPath := testPathFromName("07.00-license-front-matter")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test, WithFrontMatterComment())
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseCommentBodyWithBlankLinesGood0800(t *testing.T) {
+	// The blank lines between the indented lines of a comment are part of
+	// the comment.
+	testPath := testPathFromName("08.00-comment-body-with-blank-lines")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseCommentBodyIndentationKeptGood0801(t *testing.T) {
+	// The lines of a comment body are kept as written, with any further
+	// indentation, and do not begin other elements.
+	testPath := testPathFromName("08.01-comment-body-indentation-kept")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseCommentBodyAfterMarkerLineGood0802(t *testing.T) {
+	// A comment with blank lines beginning on the line after the marker.
+	testPath := testPathFromName("08.02-comment-body-after-marker-line")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseEmptyCommentEndsBlockQuoteGood0803(t *testing.T) {
+	// An empty comment between two block quotes keeps them apart.
+	testPath := testPathFromName("08.03-empty-comment-ends-block-quote")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
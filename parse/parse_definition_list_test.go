@@ -0,0 +1,77 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+// To enable debug output when testing, use "go test -debug"
+
+package parse
+
+import "testing"
+
+func TestParseDefinitionListNoBlankLineBad0000(t *testing.T) {
+	// A two line definition followed by a paragraph without a blank line
+	// ends the list with a warning.
+	testPath := testPathFromName("00.00-def-list-no-blank-line")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseDefinitionListClassifiersGood0100(t *testing.T) {
+	// Classifiers follow the term, separated by " : ".
+	testPath := testPathFromName("01.00-classifiers")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseDefinitionListItemsWithoutBlankLineGood0101(t *testing.T) {
+	// The term of the second item directly follows the first definition.
+	testPath := testPathFromName("01.01-items-without-blank-line")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseDefinitionListNestedDefinitionListGood0102(t *testing.T) {
+	// The first line of a definition is the term of a nested definition
+	// list.
+	testPath := testPathFromName("01.02-nested-definition-list")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseDefinitionListBlockQuoteAfterBlankLineGood0103(t *testing.T) {
+	// A blank line between a line and an indented line makes the indented
+	// line a block quote, not a definition.
+	testPath := testPathFromName("01.03-block-quote-after-blank-line")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseDefinitionListEmptyBulletBetweenListsGood0104(t *testing.T) {
+	// A bullet alone on a line between two definition lists is an empty
+	// list item, which the term of the second list ends.
+	testPath := testPathFromName("01.04-empty-bullet-between-lists")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseDefinitionListTermAfterBlockQuoteGood0105(t *testing.T) {
+	// A term that is not indented ends the block quote before it, and
+	// begins a definition list after the block quote.
+	testPath := testPathFromName("01.05-term-after-block-quote")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
@@ -0,0 +1,98 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import "testing"
+
+func TestParseDegenerateEmptyInput0000(t *testing.T) {
+	// An empty input
+	testPath := testPathFromName("00.00-empty-input")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseDegenerateSingleNewline0001(t *testing.T) {
+	// A single newline
+	testPath := testPathFromName("00.01-single-newline")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseDegenerateOnlyBlankLines0002(t *testing.T) {
+	// Only blank lines
+	testPath := testPathFromName("00.02-only-blank-lines")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseDegenerateOnlySpaces0003(t *testing.T) {
+	// Only spaces, without a newline
+	testPath := testPathFromName("00.03-only-spaces")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseDegenerateSpacesAndNewline0004(t *testing.T) {
+	// A line of spaces followed by a newline
+	testPath := testPathFromName("00.04-spaces-and-newline")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseDegenerateWhitespaceLines0005(t *testing.T) {
+	// Lines containing only spaces and tabs
+	testPath := testPathFromName("00.05-whitespace-lines")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseDegenerateSingleAdornmentRune0100(t *testing.T) {
+	// A single adornment rune is a paragraph
+	testPath := testPathFromName("01.00-single-adornment-rune")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseDegenerateShortAdornmentLine0101(t *testing.T) {
+	// An adornment line too short to be a transition is a paragraph
+	testPath := testPathFromName("01.01-short-adornment-line")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseDegenerateLoneLiteralMarker0102(t *testing.T) {
+	// A lone "::" is a literal block marker, not a transition. It is removed
+	// and the missing literal block is reported.
+	testPath := testPathFromName("01.02-lone-literal-marker")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseDegenerateSingleMultibyteRune0103(t *testing.T) {
+	// A single multibyte rune
+	testPath := testPathFromName("01.03-single-multibyte-rune")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
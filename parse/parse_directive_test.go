@@ -0,0 +1,118 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+// To enable debug output when testing, use "go test -debug"
+
+package parse
+
+import "testing"
+
+func TestParseDirectiveImageGood0000(t *testing.T) {
+	// An image directive with an argument and options, one of which is
+	// continued on the next line.
+	testPath := testPathFromName("00.00-directive-image")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseDirectiveNoteGood0001(t *testing.T) {
+	// A note directive with content beginning on the marker line.
+	testPath := testPathFromName("00.01-directive-note")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseDirectiveOptionsOnlyGood0002(t *testing.T) {
+	// A directive with options and no content.
+	testPath := testPathFromName("00.02-directive-options-only")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseDirectiveNoContentGood0003(t *testing.T) {
+	// Directives with no arguments, options, or content.
+	testPath := testPathFromName("00.03-directive-no-content")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseDirectiveUnknownNameGood0004(t *testing.T) {
+	// A directive with a name that is not known is reported as unknown.
+	testPath := testPathFromName("00.04-directive-unknown-name")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseDirectiveNoBlankLineBad0000(t *testing.T) {
+	// A directive followed by a paragraph without a blank line.
+	testPath := testPathFromName("00.00-directive-no-blankline")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseDirectiveCodeDirectiveGood0100(t *testing.T) {
+	// A code directive with blank lines, tabs, and lines starting "..".
+	testPath := testPathFromName("01.00-code-directive")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseDirectiveCodeBlockLinenosGood0101(t *testing.T) {
+	// A code-block directive with the lines numbered from one.
+	testPath := testPathFromName("01.01-code-block-linenos")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseDirectiveCodeNumberLinesStartGood0102(t *testing.T) {
+	// A code directive with the lines numbered from a given line.
+	testPath := testPathFromName("01.02-code-number-lines-start")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseDirectiveCodeWithoutLanguageGood0103(t *testing.T) {
+	// A code directive without a language argument.
+	testPath := testPathFromName("01.03-code-without-language")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseDirectiveCodeWithoutContentBad0100(t *testing.T) {
+	// A code directive without content.
+	testPath := testPathFromName("01.00-code-without-content")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseDirectiveCodeInvalidNumberLinesBad0101(t *testing.T) {
+	// A code directive with a :number-lines: value that is not a number.
+	testPath := testPathFromName("01.01-code-invalid-number-lines")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
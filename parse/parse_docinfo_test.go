@@ -0,0 +1,57 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+// To enable debug output when testing, use "go test -debug"
+
+package parse
+
+import "testing"
+
+func TestParseDocinfoDuplicateBibliographicFieldBad0000(t *testing.T) {
+	// A registered field used twice is kept as a generic field and reported
+	// with an error.
+	testPath := testPathFromName("00.00-duplicate-bibliographic-field")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test, WithDocinfo())
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseDocinfoAfterTitleGood0000(t *testing.T) {
+	// The field list following the document title is the docinfo, the
+	// field list later in the body is not converted.
+	testPath := testPathFromName("00.00-docinfo-after-title")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test, WithDocinfo())
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseDocinfoWithoutTitleGood0001(t *testing.T) {
+	// A field list at the start of a document without a title.
+	testPath := testPathFromName("00.01-docinfo-without-title")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test, WithDocinfo())
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseDocinfoFieldListNotFirstGood0002(t *testing.T) {
+	// A field list following a paragraph is not the docinfo.
+	testPath := testPathFromName("00.02-field-list-not-first")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test, WithDocinfo())
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseDocinfoFieldListInFirstOfTwoSectionsGood0003(t *testing.T) {
+	// The first of two sections is not the document title, so its field
+	// list is not converted.
+	testPath := testPathFromName("00.03-field-list-in-first-of-two-sections")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test, WithDocinfo())
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
@@ -0,0 +1,56 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+// To enable debug output when testing, use "go test -debug"
+
+package parse
+
+import "testing"
+
+func TestParseDoctestBlockGood0000(t *testing.T) {
+	// A doctest block followed by a paragraph.
+	testPath := testPathFromName("00.00-doctest-block")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseDoctestBlockMultiStatementSessionGood0001(t *testing.T) {
+	// A session with several statements, continuation lines, and output.
+	testPath := testPathFromName("00.01-multi-statement-session")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseDoctestBlockInBulletListGood0002(t *testing.T) {
+	// A doctest block in the body of a bullet list item.
+	testPath := testPathFromName("00.02-doctest-block-in-bullet-list")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseDoctestBlockQuotedLiteralBlockPromptGood0003(t *testing.T) {
+	// Lines beginning with ">>>" after "::" are a quoted literal block, not
+	// a doctest block.
+	testPath := testPathFromName("00.03-quoted-literal-block-prompt")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseDoctestBlockBelowOverlineBad0000(t *testing.T) {
+	// A doctest line directly below an overline is an incomplete title, the
+	// doctest block after the blank line is parsed.
+	testPath := testPathFromName("00.00-doctest-below-overline")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
@@ -0,0 +1,124 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+// To enable debug output when testing, use "go test -debug"
+
+package parse
+
+import "testing"
+
+func TestParseEnumListSequencesGood0000(t *testing.T) {
+	// A list of each sequence and format. A change of either begins a new
+	// list.
+	testPath := testPathFromName("00.00-sequences")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseEnumListTwoDigitNumbersGood0001(t *testing.T) {
+	// Enumerators of more than one digit
+	testPath := testPathFromName("00.01-two-digit-numbers")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseEnumListEnumeratorAtEOFGood0002(t *testing.T) {
+	// An enumerator immediately followed by the end of input is an empty
+	// item.
+	testPath := testPathFromName("00.02-enumerator-at-eof")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseEnumListRomanSequenceGood0003(t *testing.T) {
+	// Roman numerals of more than one letter
+	testPath := testPathFromName("00.03-roman-sequence")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseEnumListEnumeratorStartsParagraphBad0000(t *testing.T) {
+	// An enumerator followed by a line that is not indented and does not
+	// begin with the next enumerator begins a paragraph.
+	testPath := testPathFromName("00.00-enumerator-starts-paragraph")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseEnumListNotAnEnumeratorBad0001(t *testing.T) {
+	// Unbalanced parentheses, a missing space, and an invalid roman numeral
+	// are not enumerators.
+	testPath := testPathFromName("00.01-not-an-enumerator")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseEnumListAutoFirstItemGood0100(t *testing.T) {
+	// A list of auto enumerators is numbered from 1.
+	testPath := testPathFromName("01.00-auto-first-item")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseEnumListExplicitThenAutoGood0101(t *testing.T) {
+	// Auto enumerators continue the numbering of the explicit enumerators
+	// before them.
+	testPath := testPathFromName("01.01-explicit-then-auto")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseEnumListAutoAlphaGood0102(t *testing.T) {
+	// Auto enumerators continue an alphabetic list with the same format.
+	testPath := testPathFromName("01.02-auto-alpha")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseEnumListAutoItemBodyGood0103(t *testing.T) {
+	// The indented paragraph belongs to the first item, so the second auto
+	// item continues the list.
+	testPath := testPathFromName("01.03-auto-item-body")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseEnumListExplicitAfterAutoBad0100(t *testing.T) {
+	// An explicit enumerator does not follow an auto enumerator, so the list
+	// ends without a blank line.
+	testPath := testPathFromName("01.00-explicit-after-auto")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseEnumListAutoOtherFormatBad0101(t *testing.T) {
+	// An auto enumerator with another format does not continue the list.
+	testPath := testPathFromName("01.01-auto-other-format")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
@@ -0,0 +1,77 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+// To enable debug output when testing, use "go test -debug"
+
+package parse
+
+import "testing"
+
+func TestParseFieldListNoBlankLineBad0000(t *testing.T) {
+	// A field list followed by a paragraph without a blank line ends the
+	// list with a warning.
+	testPath := testPathFromName("00.00-field-list-no-blank-line")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseFieldListNotAFieldMarkerBad0001(t *testing.T) {
+	// Lines that begin with a colon but not with a field marker are
+	// paragraphs.
+	testPath := testPathFromName("00.01-not-a-field-marker")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseFieldListGood0000(t *testing.T) {
+	// Two fields with one line bodies.
+	testPath := testPathFromName("00.00-field-list")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseFieldListEmptyFieldBodyGood0001(t *testing.T) {
+	// Fields without a body, followed by a field with a body.
+	testPath := testPathFromName("00.01-empty-field-body")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseFieldListEscapedColonInNameGood0002(t *testing.T) {
+	// A colon escaped with a backslash, or followed by text, is part of
+	// the field name.
+	testPath := testPathFromName("00.02-escaped-colon-in-name")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseFieldListMultiLineFieldBodyGood0003(t *testing.T) {
+	// The field body continues on the lines indented more than the field
+	// marker, including after a blank line.
+	testPath := testPathFromName("00.03-multi-line-field-body")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseFieldListBelowOverlineBad0002(t *testing.T) {
+	// A field directly below an overline is an incomplete title, the field
+	// list after the blank line is parsed.
+	testPath := testPathFromName("00.02-field-below-overline")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
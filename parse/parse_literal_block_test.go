@@ -0,0 +1,120 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+// To enable debug output when testing, use "go test -debug"
+
+package parse
+
+import "testing"
+
+func TestParseLiteralBlockNoBlankLineBad0000(t *testing.T) {
+	// A literal block followed by a paragraph without a blank line ends the
+	// block with a warning.
+	testPath := testPathFromName("00.00-literal-block-no-blank-line")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseLiteralBlockExpectedBad0001(t *testing.T) {
+	// A paragraph ending with "::" that is not followed by an indented block
+	// is reported with a warning.
+	testPath := testPathFromName("00.01-literal-block-expected")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseLiteralBlockInconsistentQuotingBad0002(t *testing.T) {
+	// A quoted literal block ended by a line with a different quote is
+	// followed by an error.
+	testPath := testPathFromName("00.02-inconsistent-literal-block-quoting")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseLiteralBlockGood0000(t *testing.T) {
+	// A lone "::" paragraph introducing a literal block.
+	testPath := testPathFromName("00.00-expanded-form")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseLiteralBlockMinimizedFormGood0001(t *testing.T) {
+	// A "::" after a space, which is removed from the paragraph.
+	testPath := testPathFromName("00.01-minimized-form")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseLiteralBlockFullyMinimizedFormGood0002(t *testing.T) {
+	// A "::" after text, which is left as a single colon.
+	testPath := testPathFromName("00.02-fully-minimized-form")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseLiteralBlockAtEOFGood0003(t *testing.T) {
+	// A literal block ending the input.
+	testPath := testPathFromName("00.03-literal-block-at-eof")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseLiteralBlockInBulletListGood0004(t *testing.T) {
+	// A literal block in the body of a bullet list item.
+	testPath := testPathFromName("00.04-literal-block-in-bullet-list")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseLiteralBlockInDefinitionGood0005(t *testing.T) {
+	// A literal block in the definition of a definition list item.
+	testPath := testPathFromName("00.05-literal-block-in-definition")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseLiteralBlockEscapedMarkerGood0006(t *testing.T) {
+	// An escaped "::" does not introduce a literal block.
+	testPath := testPathFromName("00.06-escaped-literal-marker")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseLiteralBlockQuotedGood0007(t *testing.T) {
+	// A literal block quoted with ">", which is kept in the text.
+	testPath := testPathFromName("00.07-quoted-literal-block")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseLiteralBlockQuotedVerticalBarGood0008(t *testing.T) {
+	// Literal blocks quoted with "|".
+	testPath := testPathFromName("00.08-quoted-literal-block-vertical-bar")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
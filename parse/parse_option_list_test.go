@@ -0,0 +1,102 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+// To enable debug output when testing, use "go test -debug"
+
+package parse
+
+import "testing"
+
+func TestParseOptionListNoBlankLineBad0000(t *testing.T) {
+	// An option list followed by a paragraph without a blank line ends the
+	// list with a warning.
+	testPath := testPathFromName("00.00-option-list-no-blank-line")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseOptionListNotAnOptionListBad0001(t *testing.T) {
+	// Lines that begin like options but have no description, or have text
+	// after a single space, are paragraphs.
+	testPath := testPathFromName("00.01-not-an-option-list")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseOptionListGood0000(t *testing.T) {
+	// Short options with and without arguments.
+	testPath := testPathFromName("00.00-short-options")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseOptionListLongOptionsGood0001(t *testing.T) {
+	// Long options with "=" and space delimited arguments.
+	testPath := testPathFromName("00.01-long-options")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseOptionListDosVmsOptionsGood0002(t *testing.T) {
+	// Old style Unix options and DOS/VMS options.
+	testPath := testPathFromName("00.02-dos-vms-options")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseOptionListOptionSynonymsGood0003(t *testing.T) {
+	// Option groups with synonyms separated by ", ".
+	testPath := testPathFromName("00.03-option-synonyms")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseOptionListDescriptionOnNextLineGood0004(t *testing.T) {
+	// Descriptions beginning on the line after the option group.
+	testPath := testPathFromName("00.04-description-on-next-line")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseOptionListMultiLineDescriptionGood0005(t *testing.T) {
+	// Descriptions continuing on indented lines and after blank lines.
+	testPath := testPathFromName("00.05-multi-line-description")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseOptionListAngleBracketArgumentGood0006(t *testing.T) {
+	// Arguments enclosed in angle brackets, which may contain spaces.
+	testPath := testPathFromName("00.06-angle-bracket-argument")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseOptionListBelowOverlineBad0002(t *testing.T) {
+	// An option directly below an overline is an incomplete title, the
+	// option list after the blank line is parsed.
+	testPath := testPathFromName("00.02-option-below-overline")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
@@ -53,3 +53,32 @@ func TestParseTwoParagraphsWithThreeLinesEach0101(t *testing.T) {
 	eNodes := test.expectNodes()
 	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
 }
+
+func TestParseTwoParagraphsWhitespaceLine0102(t *testing.T) {
+	// Parse two paragraphs separated by a line containing only spaces
+	testPath := testPathFromName("01.02-two-para-whitespace-line")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseParagraphInvisibleControl0200(t *testing.T) {
+	// A right-to-left override in the display text of a hyperlink is kept
+	// and reported with an info message.
+	testPath := testPathFromName("02.00-rtl-override-link-text")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseParagraphInvisibleControlRejected0201(t *testing.T) {
+	// With WithBidiControlsRejected, the override is removed and reported
+	// with a warning.
+	testPath := testPathFromName("02.01-rtl-override-link-text-rejected")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test, WithBidiControlsRejected())
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
@@ -65,6 +65,26 @@ func TestParseSectionTitleGood0300(t *testing.T) {
 	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
 }
 
+func TestParseSectionTitleGood0400(t *testing.T) {
+	// A title underline followed by trailing spaces. The spaces are not
+	// part of the underline.
+	testPath := testPathFromName("04.00-underline-trailing-space")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseSectionTitleGood0500(t *testing.T) {
+	// A short adornment line directly below the underline of a title is
+	// paragraph text.
+	testPath := testPathFromName("05.00-short-adornment-below-title")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
 func TestParseSectionTitleBad0000(t *testing.T) {
 	// Tests for severe system messages when the sections are indented.
 	testPath := testPathFromName("00.00-unexpected-titles")
@@ -112,6 +132,26 @@ func TestParseSectionTitleBad0202(t *testing.T) {
 	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
 }
 
+func TestParseSectionTitleBad0300(t *testing.T) {
+	// A title "underline" that mixes adornment characters. Since it is
+	// not an adornment, the title and underline are a paragraph.
+	testPath := testPathFromName("03.00-mixed-underline")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseSectionTitleBad0301(t *testing.T) {
+	// A title "underline" of alternating adornment characters, which is
+	// also paragraph text.
+	testPath := testPathFromName("03.01-alternating-underline")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
 func TestParseSectionLevelGood0000(t *testing.T) {
 	// Tests section level return to level one after three subsections.
 	testPath := testPathFromName("00.00-section-level-return")
@@ -159,6 +199,17 @@ func TestParseSectionLevelGood0200(t *testing.T) {
 	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
 }
 
+func TestParseSectionLevelGood0300(t *testing.T) {
+	// A document title with an overline followed by sections using the
+	// same rune without an overline. These are different section styles,
+	// so the sections are level two.
+	testPath := testPathFromName("03.00-overline-title-same-rune-sections")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
 func TestParseSectionLevelBad0000(t *testing.T) {
 	// Test section level return on bad level 2 section adornment
 	testPath := testPathFromName("00.00-bad-subsection-order")
@@ -188,6 +239,16 @@ func TestParseSectionLevelBad0100(t *testing.T) {
 	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
 }
 
+func TestParseSectionLevelBad0200(t *testing.T) {
+	// A section using the style of level three directly below a level one
+	// section is inconsistent.
+	testPath := testPathFromName("02.00-existing-level-too-deep")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
 func TestParseSectionTitleWithOverlineGood0000(t *testing.T) {
 	// Test simple section with title overline.
 	testPath := testPathFromName("00.00-title-overline")
@@ -215,6 +276,16 @@ func TestParseSectionTitleWithOverlineGood0200(t *testing.T) {
 	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
 }
 
+func TestParseSectionTitleWithOverlineGood0300(t *testing.T) {
+	// A title that begins and ends with the adornment rune of its
+	// overline and underline.
+	testPath := testPathFromName("03.00-adornment-rune-title")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
 func TestParseSectionTitleWithOverlineBad0000(t *testing.T) {
 	// Test section title with overline, but no underline.
 	testPath := testPathFromName("00.00-inset-title-missing-underline")
@@ -308,6 +379,16 @@ func TestParseSectionTitleWithOverlineBad0400(t *testing.T) {
 	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
 }
 
+func TestParseSectionTitleWithOverlineBad0401(t *testing.T) {
+	// An indented title with a short overline and an underline that is
+	// not indented.
+	testPath := testPathFromName("04.01-indented-title-unindented-underline")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
 func TestParseSectionTitleWithOverlineBad0500(t *testing.T) {
 	// Tests ".." overline (which is a comment element).
 	testPath := testPathFromName("05.00-two-char-section-title")
@@ -317,6 +398,60 @@ func TestParseSectionTitleWithOverlineBad0500(t *testing.T) {
 	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
 }
 
+func TestParseSectionTitleWithOverlineBad0600(t *testing.T) {
+	// An overline and a title at the end of the input.
+	testPath := testPathFromName("06.00-overline-title-at-end")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseSectionTitleWithOverlineBad0601(t *testing.T) {
+	// An overline and a title followed by a blank line.
+	testPath := testPathFromName("06.01-overline-title-blank-line")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseSectionTitleWithOverlineBad0602(t *testing.T) {
+	// An overline and a title followed by a line of text.
+	testPath := testPathFromName("06.02-overline-title-text-line")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseSectionTitleWithOverlineBad0603(t *testing.T) {
+	// An overline and a title followed by an indented comment marker.
+	testPath := testPathFromName("06.03-overline-title-indented-comment")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseSectionTitleWithOverlineBad0604(t *testing.T) {
+	// An overline and an inset title followed by an indented comment marker.
+	testPath := testPathFromName("06.04-overline-inset-title-indented-comment")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseSectionTitleWithOverlineBad0605(t *testing.T) {
+	// An overline and a title followed by an indented comment with text.
+	testPath := testPathFromName("06.05-overline-title-indented-comment-text")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
 func TestParseSectionTitleNumberedGood0000(t *testing.T) {
 	// Tests lexing a section where the title begins with a number.
 	testPath := testPathFromName("00.00-numbered-title")
@@ -335,3 +470,157 @@ func TestParseSectionTitleNumberedGood0100(t *testing.T) {
 	eNodes := test.expectNodes()
 	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
 }
+
+func TestParseSectionMaxDepthGood0000(t *testing.T) {
+	// Without a depth limit, all six levels of sections are nested.
+	testPath := testPathFromName("00.00-six-levels-no-limit")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseSectionMaxDepthStrictGood0001(t *testing.T) {
+	// With a strict limit of three levels, the deeper sections are kept and
+	// each contains an error message.
+	testPath := testPathFromName("00.01-six-levels-limit-strict")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test, WithMaxSectionDepth(3, DepthStrict))
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseSectionMaxDepthFlattenGood0002(t *testing.T) {
+	// With a lenient limit of three levels, the titles of the deeper
+	// sections become paragraphs of the third level section, each preceded
+	// by an info message.
+	testPath := testPathFromName("00.02-six-levels-limit-flatten")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test, WithMaxSectionDepth(3, DepthFlatten))
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseSectionBlankSeparatedAdornmentGood0000(t *testing.T) {
+	// An adornment line between blank lines is a transition.
+	testPath := testPathFromName("00.00-blank-separated-transition")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseSectionBlankSeparatedAdornmentGood0001(t *testing.T) {
+	// An adornment line directly below text is an underline.
+	testPath := testPathFromName("00.01-blank-separated-underline")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseSectionBlankSeparatedAdornmentGood0002(t *testing.T) {
+	// An adornment line after a blank line and above text is an overline.
+	testPath := testPathFromName("00.02-blank-separated-overline")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseSectionTitleLeadingSpaceGood0000(t *testing.T) {
+	// A no-break space is not indentation by default, it is part of the
+	// title.
+	testPath := testPathFromName("00.00-nbsp-title")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseSectionTitleLeadingSpaceBad0000(t *testing.T) {
+	// With WithNBSPIndent, the no-break space indents the title, which is
+	// parsed as a title indented with spaces.
+	testPath := testPathFromName("00.00-nbsp-title-lenient")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test, WithNBSPIndent())
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseSectionTitleLeadingSpaceGood0100(t *testing.T) {
+	// Spaces that are never indentation, such as U+3000 and U+2003, are
+	// part of the title and reported.
+	testPath := testPathFromName("01.00-wide-space-title")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseSectionTitleLeadingSpaceGood0101(t *testing.T) {
+	// WithNBSPIndent does not change the spaces other than U+00A0.
+	testPath := testPathFromName("01.01-wide-space-title-lenient")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test, WithNBSPIndent())
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseSectionTitleInvisibleControlGood0000(t *testing.T) {
+	// An invisible control beginning a title is kept and reported with an
+	// info message in the section.
+	testPath := testPathFromName("00.00-invisible-control-title")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseSectionTitleInvisibleControlGood0001(t *testing.T) {
+	// With WithBidiControlsRejected, the control is removed from the title
+	// and reported with a warning.
+	testPath := testPathFromName("00.01-invisible-control-title-rejected")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test, WithBidiControlsRejected())
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseSectionBlankSeparatedAdornmentBad0000(t *testing.T) {
+	// A bullet list item below an overline is an incomplete title.
+	testPath := testPathFromName("00.00-overline-bullet-item")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseSectionBlankSeparatedAdornmentBad0001(t *testing.T) {
+	// A comment below an overline at the end of the input is an
+	// incomplete title.
+	testPath := testPathFromName("00.01-overline-comment")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseSectionBlankSeparatedAdornmentBad0002(t *testing.T) {
+	// A directive below an overline is an incomplete title.
+	testPath := testPathFromName("00.02-overline-directive")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseSectionBlankSeparatedAdornmentBad0100(t *testing.T) {
+	// A short overline and the bullet list item below it are paragraph
+	// text, reported with an info message.
+	testPath := testPathFromName("01.00-short-overline-bullet-item")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
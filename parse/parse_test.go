@@ -10,31 +10,39 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
-	"io/ioutil"
+	"io/fs"
 	"math"
 	"os"
-	"path/filepath"
+	"path"
 	"reflect"
+	"runtime"
 	"strconv"
 	"strings"
 	"testing"
 
-	"code.google.com/p/go.text/unicode/norm"
 	"github.com/demizer/go-elog"
+	"github.com/demizer/go-rst/internal/corpus"
+	"github.com/demizer/go-rst/internal/expect"
 )
 
-func init() { SetDebug() }
+var debug = flag.Bool("debug", false, "Enable debug output.")
 
-// SetDebug is typically called from the init() function in a test file.
-// SetDebug parses debug flags passed to the test binary and also sets the
-// template for logging output.
-func SetDebug() {
-	var debug bool
-
-	flag.BoolVar(&debug, "debug", false, "Enable debug output.")
+// TestMain parses the flags passed to the test binary before running the
+// tests. The testing flags are only registered once the test binary starts, so
+// flag.Parse cannot be called from init(). The lexer progress check is enabled
+// for all tests.
+func TestMain(m *testing.M) {
 	flag.Parse()
+	SetDebug()
+	maxStalledStates = 100
+	os.Exit(m.Run())
+}
 
-	if debug {
+// SetDebug is called from TestMain after the flags have been parsed. SetDebug
+// enables debug output if the debug flag was passed to the test binary and
+// also sets the template for logging output.
+func SetDebug() {
+	if *debug {
 		log.SetLevel(log.LEVEL_DEBUG)
 	}
 
@@ -51,8 +59,8 @@ func SetDebug() {
 	log.SetFlags(log.LdebugTreeFlags)
 }
 
-// Contains a single test with data loaded from test files in the testdata
-// directory
+// Contains a single test with data loaded from test files in the
+// internal/corpus directory
 type Test struct {
 	path     string // The path including directory and basename
 	data     string // The input data to be parsed
@@ -65,7 +73,7 @@ type Test struct {
 // data.
 func (l Test) expectNodes() (nl []interface{}) {
 	if err := json.Unmarshal([]byte(l.nodeData), &nl); err != nil {
-		panic(fmt.Errorf("JSON error: ", err))
+		panic(fmt.Errorf("JSON error: %s", err))
 	}
 	return
 }
@@ -74,26 +82,28 @@ func (l Test) expectNodes() (nl []interface{}) {
 // occurs if there is an error decoding the JSON data.
 func (l Test) expectItems() (lexItems []item) {
 	if err := json.Unmarshal([]byte(l.itemData), &lexItems); err != nil {
-		panic(fmt.Errorf("JSON error: ", err))
+		panic(fmt.Errorf("JSON error: %s", err))
 	}
 	return
 }
 
-// Contains absolute file paths for the test data
+// Contains the paths of the test data in corpus.Files
 var TESTDATA_FILES []string
 
-// testPathsFromDirectory walks through the file tree in the testdata directory
-// containing all of the tests and returns a string slice of all the discovered
-// paths.
-func testPathsFromDirectory(dir string) (paths []string) {
-	wFunc := func(p string, info os.FileInfo, err error) error {
-		path, _ := filepath.Abs(p)
-		if filepath.Ext(path) == ".rst" {
-			paths = append(paths, path[:len(path)-4])
+// testPathsFromDirectory walks through the file tree of fsys containing all of
+// the tests and returns a string slice of all the discovered paths, without
+// their extension.
+func testPathsFromDirectory(fsys fs.FS) (paths []string) {
+	wFunc := func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path.Ext(p) == ".rst" {
+			paths = append(paths, p[:len(p)-4])
 		}
 		return nil
 	}
-	err := filepath.Walk(dir, wFunc)
+	err := fs.WalkDir(fsys, ".", wFunc)
 	if err != nil {
 		panic(err)
 	}
@@ -103,10 +113,10 @@ func testPathsFromDirectory(dir string) (paths []string) {
 // testPathFromName loops through TESTDATA_FILES until name is matched.
 func testPathFromName(name string) (path string) {
 	if len(TESTDATA_FILES) < 1 {
-		TESTDATA_FILES = testPathsFromDirectory("../testdata")
+		TESTDATA_FILES = testPathsFromDirectory(corpus.Files)
 	}
 	for _, p := range TESTDATA_FILES {
-		if p[len(p)-len(name):] == name {
+		if strings.HasSuffix(p, name) {
 			return p
 		}
 	}
@@ -115,7 +125,7 @@ func testPathFromName(name string) (path string) {
 
 func LoadLexTest(t *testing.T, path string) (test *Test) {
 	iDPath := path + ".rst"
-	inputData, err := ioutil.ReadFile(iDPath)
+	inputData, err := corpus.Files.ReadFile(iDPath)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -123,7 +133,7 @@ func LoadLexTest(t *testing.T, path string) (test *Test) {
 		t.Fatalf("\"%s\" is empty!", iDPath)
 	}
 	itemFPath := path + "-items.json"
-	itemData, err := ioutil.ReadFile(itemFPath)
+	itemData, err := corpus.Files.ReadFile(itemFPath)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -139,7 +149,7 @@ func LoadLexTest(t *testing.T, path string) (test *Test) {
 
 func LoadParseTest(t *testing.T, path string) (test *Test) {
 	iDPath := path + ".rst"
-	inputData, err := ioutil.ReadFile(iDPath)
+	inputData, err := corpus.Files.ReadFile(iDPath)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -147,7 +157,7 @@ func LoadParseTest(t *testing.T, path string) (test *Test) {
 		t.Fatalf("\"%s\" is empty!", iDPath)
 	}
 	nDPath := path + "-nodes.json"
-	nodeData, err := ioutil.ReadFile(nDPath)
+	nodeData, err := corpus.Files.ReadFile(nDPath)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -161,322 +171,57 @@ func LoadParseTest(t *testing.T, path string) (test *Test) {
 	}
 }
 
-type checkNode struct {
-	t          *testing.T
-	testPath   string
-	pNodeName  string
-	pFieldName string
-	pFieldVal  interface{}
-	pFieldType reflect.Type
-	eFieldName string
-	eFieldVal  interface{}
-	eFieldType reflect.Type
-	id         int
-}
-
-func (c *checkNode) error(args ...interface{}) {
-	c.t.Error(args...)
-}
-
-func (c *checkNode) errorf(format string, args ...interface{}) {
-	c.t.Errorf(format, args...)
-}
-
-func (c *checkNode) dError() {
-	var got, exp string
-
-	switch c.pFieldVal.(type) {
-	case ID:
-		got = c.pFieldVal.(ID).String()
-		exp = strconv.Itoa(int(c.eFieldVal.(float64)))
-	case NodeType:
-		got = c.pFieldVal.(NodeType).String()
-		exp = c.eFieldVal.(string)
-	case StartPosition:
-		got = c.pFieldVal.(StartPosition).String()
-		exp = strconv.Itoa(int(c.eFieldVal.(float64)))
-	case Line:
-		got = c.pFieldVal.(Line).String()
-		exp = strconv.Itoa(int(c.eFieldVal.(float64)))
-	case systemMessageLevel:
-		pNum := int(c.pFieldVal.(systemMessageLevel))
-		pNumStr := " (" + strconv.Itoa(pNum) + ")"
-		got = c.pFieldVal.(systemMessageLevel).String() + pNumStr
-		smsLvl := int(systemMessageLevelFromString(c.eFieldVal.(string)))
-		eNumStr := " (" + strconv.Itoa(smsLvl) + ")"
-		exp = c.eFieldVal.(string) + eNumStr
-	case string:
-		got = c.pFieldVal.(string)
-		exp = c.eFieldVal.(string)
-	case int:
-		got = strconv.Itoa(c.pFieldVal.(int))
-		exp = strconv.Itoa(int(c.eFieldVal.(float64)))
-	case rune:
-		got = string(c.pFieldVal.(rune))
-		exp = string(c.eFieldVal.(rune))
-	}
-	eTemp := "(ID: %2d) Got: %s = %q\n\t\t Expect: %s = %q\n\n"
-	c.t.Errorf(eTemp, c.id, c.pFieldName, got, c.eFieldName, exp)
-}
-
-func (c *checkNode) updateState(eKey string, eVal interface{},
-	pVal reflect.Value) bool {
-
-	// Expected parser metadata
-	c.eFieldName = eKey
-	c.eFieldVal = eVal
-	c.eFieldType = reflect.TypeOf(c.eFieldVal)
-
-	// Actual parsed metadata
-	c.pNodeName = pVal.Type().Name()
-	c.pFieldName = strings.ToUpper(string(c.eFieldName[0]))
-	c.pFieldName += c.eFieldName[1:]
-
-	if c.pFieldName == "Id" {
-		// Overide for uppercase ID
-		c.pFieldName = "ID"
-	}
-
-	if !pVal.FieldByName(c.pFieldName).IsValid() {
-		return false
-	}
-
-	c.pFieldVal = pVal.FieldByName(c.pFieldName).Interface()
-	c.pFieldType = pVal.FieldByName(c.pFieldName).Type()
-
-	// Overline adornment nodes can be null
-	if c.eFieldName == "overLine" && c.eFieldVal == nil {
-		return false
-	} else if c.eFieldVal == nil {
-		c.dError()
-		return false
-	}
-
-	return true
-}
-
-// checkMatchingFields compares the expected node output retrieved from the
-// nodes.json file and the actual parser NodeList output. Returns an error if
-// a mismatch is found.
-func (c *checkNode) checkMatchingFields(eNodes interface{}, pNode Node) error {
-	if eNodes == nil || pNode == nil {
-		panic("arguments must not be nil!")
-	}
-	// If the value is missing in eNodes and nil in pNode than we can
-	// exclude it.
-	eFields := eNodes.(map[string]interface{})
-	pNodeVal := reflect.Indirect(reflect.ValueOf(pNode))
-	// Check expected node to parsed node
-	for eName, _ := range eFields {
-		var sfName string
-		if eName == "id" {
-			sfName = "ID"
-		} else {
-			sfName = strings.ToUpper(eName[0:1]) + eName[1:]
-		}
-		_, in := pNodeVal.Type().FieldByName(sfName)
-		if !in {
-			nName := reflect.TypeOf(pNode)
-			return fmt.Errorf("Node (%s) missing field %q\n",
-				nName, sfName)
-		}
-	}
-	// Compare pNode against eNodes
-	for i := 0; i < pNodeVal.NumField(); i++ {
-		pName := pNodeVal.Type().Field(i).Tag.Get("json")
-		if pName == "" {
-			log.SetFlags(log.LstdFlags)
-			log.Criticalf("pName == nil; Check struct tags!\n", pName)
-			os.Exit(1)
-		}
-		pVal := pNodeVal.Field(i).Interface()
-		eFields := eNodes.(map[string]interface{})
-		switch pName {
-		case "indentLength":
-			// Some title nodes aren't indented.
-			if pVal == 0 {
-				continue
-			}
-		case "startPosition":
-			// Most nodes begin at position one in the line,
-			// therefore we can ignore them if it hasn't been
-			// specified in the expected nodes.
-			if pVal.(StartPosition).Position() == 0 ||
-				pVal.(StartPosition).Position() == 1 {
-				continue
-			}
-		case "line":
-			// zero, then we ignore it.  systemMessage literal
-			// block nodes have no line position.
-			if pVal.(Line).LineNumber() == 0 {
-				continue
-			}
-		case "overLine":
-			// Some sections don't have overlines
-			if eFields[pName] == nil &&
-				pVal.(*AdornmentNode) == nil {
-				continue
-			}
-		case "nodeList":
-			// Some Nodes don't have child nodes.
-			if eFields[pName] == nil && pVal.(NodeList) == nil {
-				continue
-			}
-		case "text":
-			// Some Nodes don't have text.
-			if eFields[pName] == nil && pVal.(string) == "" {
-				continue
-			}
-		case "length":
-			if eFields[pName] == nil && pVal == 0 {
-				continue
-			}
-		}
-		eNode := eNodes.(map[string]interface{})
-		if eNode[pName] == nil {
-			tmp := "Node ID=%.0f missing field %q\n\t   " +
-				"Parser got: %q == %v\n"
-			return fmt.Errorf(tmp, eNode["id"], pName,
-				pName, pVal)
-		}
-	}
-	return nil
-}
-
-// checkFields is a recursive function that compares the expected node output
-// to the parser output comparing the two objects field by field. eNodes is
-// unmarshaled json input and pNode is the parser node to check.
-func (c *checkNode) checkFields(eNodes interface{}, pNode Node) {
-	if eNodes == nil || pNode == nil {
-		panic("arguments cannot be nil!")
-	}
-	c.id = int(eNodes.(map[string]interface{})["id"].(float64))
-	if err := c.checkMatchingFields(eNodes, pNode); err != nil {
-		c.t.Error(err)
-	}
-	for eKey, eVal := range eNodes.(map[string]interface{}) {
-		pVal := reflect.Indirect(reflect.ValueOf(pNode))
-		if c.updateState(eKey, eVal, pVal) == false {
+// diffExpectedNodes compares nodes to the expected nodes eNodes decoded by
+// package expect using DiffNodeLists. The start positions that are not given
+// by the expected nodes are not compared.
+func diffExpectedNodes(eNodes, nodes NodeList) (diffs []NodeDiff) {
+	for _, d := range DiffNodeLists(eNodes, nodes) {
+		if expect.Omitted(d.Field, d.A, d.B) {
 			continue
 		}
-		switch c.eFieldName {
-		case "text":
-			nExpect := norm.NFC.String(c.eFieldVal.(string))
-			if nExpect != c.pFieldVal.(string) {
-				c.dError()
-			}
-		case "type":
-			if c.eFieldVal != c.pFieldVal.(NodeType).String() {
-				c.dError()
-			}
-		case "messageType":
-			if c.eFieldVal != c.pFieldVal.(parserMessage).String() {
-				c.dError()
-			}
-		case "id":
-			if c.eFieldVal != float64(c.pFieldVal.(ID)) {
-				c.dError()
-			}
-		case "level", "length", "indentLength":
-			if c.eFieldVal != float64(c.pFieldVal.(int)) {
-				c.dError()
-			}
-		case "line":
-			if c.eFieldVal != float64(c.pFieldVal.(Line)) {
-				c.dError()
-			}
-		case "startPosition":
-			if c.eFieldVal != float64(c.pFieldVal.(StartPosition)) {
-				c.dError()
-			}
-		case "indent", "overLine", "title", "underLine":
-			c.checkFields(c.eFieldVal, c.pFieldVal.(Node))
-		case "term", "definition":
-			c.checkFields(c.eFieldVal, c.pFieldVal.(Node))
-		case "nodeList":
-			len1 := len(c.eFieldVal.([]interface{}))
-			len2 := len(c.pFieldVal.(NodeList))
-			if len1 != len2 {
-				log.SetFlags(log.LstdFlags)
-				iVal := c.eFieldVal.([]interface{})[0]
-				id := iVal.(map[string]interface{})["id"]
-				// DO NOT REMOVE SPD CALLS
-				log.Criticalf("\n%d Parse NodeList Nodes\n\n", len2)
-				spd.Dump(pNode)
-				log.Criticalf("\n%d Expected NodeList Nodes\n\n", len1)
-				spd.Dump(eNodes)
-				fmt.Println()
-				// DO NOT REMOVE SPD CALLS
-				eTmp := "Expected NodeList values (len=%d) " +
-					"and parsed NodeList values (len=%d) " +
-					"do not match beginning at item ID: %d"
-				c.t.Fatalf(eTmp, len1, len2, id)
-			}
-			for num, node := range c.eFieldVal.([]interface{}) {
-				// Store and reset the parser value, otherwise
-				// a panic will occur on the next iteration
-				pFieldVal := c.pFieldVal
-				c.checkFields(node, c.pFieldVal.(NodeList)[num])
-				c.pFieldVal = pFieldVal
-			}
-		case "rune":
-			if c.eFieldVal != string(c.pFieldVal.(rune)) {
-				c.dError()
-			}
-		case "severity":
-			pFVal := c.pFieldVal.(systemMessageLevel).String()
-			if c.eFieldVal != pFVal {
-				c.dError()
-			}
-		case "bullet":
-			if c.eFieldVal.(string) != c.pFieldVal.(string) {
-				c.dError()
-			}
-		case "enumType":
-			if c.eFieldVal != c.pFieldVal.(EnumListType).String() {
-				c.dError()
-			}
-		case "affix":
-			if c.eFieldVal != c.pFieldVal.(EnumAffixType).String() {
-				c.dError()
-			}
-		default:
-			c.t.Errorf("Type %q case is not implemented in checkFields!", c.eFieldName)
-		}
+		diffs = append(diffs, d)
 	}
-
+	return
 }
 
 // checkParseNodes compares the expected parser output (*_nodes.json) against
-// the actual parser output node by node.
+// the actual parser output using diffExpectedNodes. Each difference is
+// reported with the path of the node in the tree.
 func checkParseNodes(t *testing.T, eTree []interface{}, pNodes []Node,
 	testPath string) {
 
-	state := &checkNode{t: t, testPath: testPath}
-
-	if len(pNodes) != len(eTree) {
-		log.SetFlags(log.LstdFlags)
-		log.Criticalf("\n%d Parse Nodes\n\n", len(pNodes))
-		spd.Dump(pNodes)
-		log.Criticalf("\n%d Expected Nodes\n\n", len(eTree))
-		spd.Dump(eTree)
-		fmt.Println("\n")
-		log.Criticalln("The number of parsed nodes does not match expected nodes!")
-		os.Exit(1)
+	var eNodes NodeList
+	if err := expect.Decode(eTree, &eNodes, expectTypes); err != nil {
+		t.Fatalf("%s-nodes.json: %s", testPath, err)
 	}
 
-	for eNum, eNode := range eTree {
-		state.checkFields(eNode, pNodes[eNum])
+	for _, d := range diffExpectedNodes(eNodes, pNodes) {
+		t.Errorf("%s: %s.%s\n\t    Got: %#v\n\t Expect: %#v\n\n",
+			testPath, d.Path, d.Field, d.B, d.A)
 	}
 
-	return
+	for _, n := range pNodes {
+		checkNodeText(t, testPath, n)
+	}
 }
 
-// parseTest initiates the parser and parses a test using test.data is input.
-func parseTest(t *testing.T, test *Test) (tree *Tree) {
+// parseTest parses the input of test. The input is built again from the
+// events of ParseEvents, which must not change the tree.
+func parseTest(t *testing.T, test *Test, opts ...ParseOption) (tree *Tree) {
 	log.Debugf("Test path: %s\n", test.path)
 	log.Debugf("Test Input:\n-----------\n%s\n----------\n", test.data)
-	tree, _ = Parse(test.path, test.data)
+	tree, _ = Parse(test.path, test.data, opts...)
+	var b Builder
+	if err := ParseEvents(test.path, test.data, &b, opts...); err != nil {
+		t.Errorf("%s: ParseEvents: %s", test.path, err)
+	}
+	if err := b.Err(); err != nil {
+		t.Errorf("%s: Builder: %s", test.path, err)
+	}
+	for _, d := range DiffNodeLists(tree.Nodes, b.Nodes) {
+		t.Errorf("%s: events changed %s.%s\n\t    Got: %#v\n\t"+
+			" Expect: %#v\n\n", test.path, d.Path, d.Field, d.B, d.A)
+	}
 	return
 }
 
@@ -615,10 +360,10 @@ var treeNextTests = []struct {
 	Peek4Tok *item
 }{
 	{
-		name:    "Next no input",
-		input:   "",
-		nextNum: 1,
-		// ZedToken should be nil
+		name:     "Next no input",
+		input:    "",
+		nextNum:  1,
+		ZedToken: &item{Type: itemEOF, Line: 1, StartPosition: 1},
 	},
 	{
 		name:     "Single next from start",
@@ -662,7 +407,7 @@ var treeNextTests = []struct {
 	},
 	{
 		name:     "Sextuple next",
-		input:    "Test\n=====\n\nParagraph.\n\n",
+		input:    "Test\n=====\n\nParagraph.\n\n\n",
 		nextNum:  6,
 		Back4Tok: &item{Type: itemSectionAdornment, Text: "====="},
 		Back3Tok: &item{Type: itemBlankLine, Text: "\n"},
@@ -672,7 +417,7 @@ var treeNextTests = []struct {
 	},
 	{
 		name:     "Septuple next",
-		input:    "Test\n=====\n\nParagraph.\n\n",
+		input:    "Test\n=====\n\nParagraph.\n\n\n",
 		nextNum:  7,
 		Back4Tok: &item{Type: itemBlankLine, Text: "\n"},
 		Back3Tok: &item{Type: itemParagraph, Text: "Paragraph."},
@@ -722,12 +467,12 @@ func TestTreeNext(t *testing.T) {
 		if tr.token[tPos].Type != val.Type {
 			t.Errorf("Test: %q\n\t    "+
 				"Got: token[%s].Type = %q, Expect: %q\n\n",
-				tr.Name, tPos, tr.token[tPos].Type, val.Type)
+				tr.Name, tName, tr.token[tPos].Type, val.Type)
 		}
 		if tr.token[tPos].Text != val.Text && val.Text != "" {
 			t.Errorf("Test: %q\n\t    "+
 				"Got: token[%s].Text = %q, Expect: %q\n\n",
-				tr.Name, tPos, tr.token[tPos].Text, val.Text)
+				tr.Name, tName, tr.token[tPos].Text, val.Text)
 		}
 	}
 	for _, tt := range treeNextTests {
@@ -798,8 +543,9 @@ var treePeekTests = []struct {
 		Peek4Tok: &item{Type: itemBlankLine, Text: "\n"},
 	},
 	{
-		name:    "Peek on no input",
-		peekNum: 1,
+		name:     "Peek on no input",
+		peekNum:  1,
+		Peek1Tok: &item{Type: itemEOF, Line: 1, StartPosition: 1},
 	},
 }
 
@@ -1068,6 +814,27 @@ var testSectionLevelsAdd = []struct {
 			},
 		},
 	},
+	{
+		name: "Test overlined level one with same rune underlined sections",
+		pSecs: []*testSectionLevelSectionNode{
+			{node: shortSectionNode{
+				id: 1, level: 1, oRune: '=', uRune: '=',
+			}},
+			{node: shortSectionNode{id: 2, level: 2, uRune: '='}},
+			{node: shortSectionNode{id: 3, level: 2, uRune: '='}},
+		},
+		eLvls: []*testSectionLevelExpectLevels{
+			{rChar: '=', level: 1, overLine: true,
+				nodes: []shortSectionNode{
+					{level: 1, oRune: '=', uRune: '='},
+				},
+			},
+			{rChar: '=', level: 2, nodes: []shortSectionNode{
+				{level: 2, uRune: '='},
+				{level: 2, uRune: '='},
+			}},
+		},
+	},
 }
 
 func testSectionLevelsAddCheckEqual(t *testing.T, testName string,
@@ -1281,24 +1048,188 @@ func TestSectionLevelsLast(t *testing.T) {
 	}
 }
 
-func TestSystemMessageLevelFrom(t *testing.T) {
-	name := "Test systemMessageLevel with levelInfo"
-	test0 := ""
-	if -1 != systemMessageLevelFromString(test0) {
-		t.Errorf("Test: %q\n\t    "+
-			"Got: systemMessageLevel = %q, Expect: %q\n\n",
-			name, systemMessageLevelFromString(test0), -1)
+func TestParseLevel(t *testing.T) {
+	var levelTests = []struct {
+		name   string
+		input  string
+		expect SystemMessageLevel
+	}{
+		{"Upper case name", "INFO", LevelInfo},
+		{"Lower case name", "warning", LevelWarning},
+		{"Mixed case name", "Error", LevelError},
+		{"Numeric form", "4", LevelSevere},
+		{"Numeric form", "1", LevelInfo},
 	}
-	test1 := "INFO"
-	if levelInfo != systemMessageLevelFromString(test1) {
-		t.Errorf("Test: %q\n\t    "+
-			"Got: systemMessageLevel = %q, Expect: %q\n\n",
-			name, systemMessageLevelFromString(test1), levelInfo)
+	for _, tt := range levelTests {
+		lvl, err := ParseLevel(tt.input)
+		if err != nil {
+			t.Errorf("Test: %q\n\t    Got: err = %q, Expect: nil\n\n",
+				tt.name, err)
+		} else if lvl != tt.expect {
+			t.Errorf("Test: %q\n\t    "+
+				"Got: SystemMessageLevel = %s, Expect: %s\n\n",
+				tt.name, lvl, tt.expect)
+		}
 	}
-	test2 := "SEVERE"
-	if levelInfo != systemMessageLevelFromString(test1) {
-		t.Errorf("Test: %q\n\t    "+
-			"Got: systemMessageLevel = %q, Expect: %q\n\n",
-			name, systemMessageLevelFromString(test2), levelSevere)
+	for _, input := range []string{"", "fatal", "0", "5"} {
+		_, err := ParseLevel(input)
+		if err == nil {
+			t.Errorf("Test: %q\n\t    Got: err = nil, Expect: error\n\n",
+				input)
+			continue
+		}
+		for _, name := range systemMessageLevels {
+			if !strings.Contains(err.Error(), name) {
+				t.Errorf("Test: %q\n\t    Got: err = %q, "+
+					"Expect: error listing %q\n\n", input, err, name)
+			}
+		}
+	}
+}
+
+func TestSystemMessageLevelText(t *testing.T) {
+	for lvl := LevelInfo; lvl <= LevelSevere; lvl++ {
+		text, err := lvl.MarshalText()
+		if err != nil {
+			t.Errorf("MarshalText(%d): err = %q, Expect: nil", lvl, err)
+			continue
+		}
+		var got SystemMessageLevel
+		if err := got.UnmarshalText(text); err != nil || got != lvl {
+			t.Errorf("UnmarshalText(%q): Got: %s (err = %v), Expect: %s",
+				text, got, err, lvl)
+		}
+	}
+	if _, err := SystemMessageLevel(-1).MarshalText(); err == nil {
+		t.Errorf("MarshalText(-1): Got: err = nil, Expect: error")
+	}
+}
+
+func TestSystemMessageLevelJSON(t *testing.T) {
+	for lvl := LevelInfo; lvl <= LevelSevere; lvl++ {
+		in := SystemMessageNode{Type: NodeSystemMessage, Severity: lvl}
+		data, err := json.Marshal(in)
+		if err != nil {
+			t.Errorf("json.Marshal(%s): err = %q", lvl, err)
+			continue
+		}
+		exp := `"severity":"` + lvl.String() + `"`
+		if !strings.Contains(string(data), exp) {
+			t.Errorf("json.Marshal(%s): Got: %s, Expect: %s", lvl, data,
+				exp)
+		}
+		var out struct {
+			Severity SystemMessageLevel `json:"severity"`
+		}
+		if err := json.Unmarshal(data, &out); err != nil ||
+			out.Severity != lvl {
+			t.Errorf("json.Unmarshal(%s): Got: %s (err = %v), Expect: %s",
+				data, out.Severity, err, lvl)
+		}
+	}
+}
+
+func TestParserMessageJSON(t *testing.T) {
+	in := SystemMessageNode{Type: NodeSystemMessage,
+		MessageType: severeIncompleteSectionTitle}
+	data, err := json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := `"messageType":"severeIncompleteSectionTitle"`
+	if !strings.Contains(string(data), exp) {
+		t.Errorf("json.Marshal: Got: %s, Expect: %s", data, exp)
+	}
+	var out struct {
+		MessageType parserMessage `json:"messageType"`
+	}
+	if err := json.Unmarshal(data, &out); err != nil ||
+		out.MessageType != severeIncompleteSectionTitle {
+		t.Errorf("json.Unmarshal(%s): Got: %s (err = %v), Expect: "+
+			"severeIncompleteSectionTitle", data, out.MessageType, err)
+	}
+}
+
+// checkNodeText reports the text of n and its children that ends a line with
+// a space or a tab, or contains a blank line. The text of literal blocks and
+// code blocks is not checked, comments can contain blank lines.
+func checkNodeText(t *testing.T, name string, n Node) {
+	v := reflect.Indirect(reflect.ValueOf(n))
+	if text := v.FieldByName("Text"); text.IsValid() &&
+		n.NodeType() != NodeLiteralBlock &&
+		n.NodeType() != NodeCodeBlock {
+		for _, line := range strings.Split(text.String(), "\n") {
+			if strings.TrimRight(line, " \t") != line {
+				t.Errorf("%s: node ID=%d: line %q ends with "+
+					"whitespace", name, n.IDNumber(), line)
+			}
+		}
+		if strings.Contains(text.String(), "\n\n") &&
+			n.NodeType() != NodeComment {
+			t.Errorf("%s: node ID=%d: text %q contains a blank line",
+				name, n.IDNumber(), text.String())
+		}
+	}
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Field(i)
+		if nl, ok := f.Interface().(NodeList); ok {
+			for _, c := range nl {
+				checkNodeText(t, name, c)
+			}
+		} else if f.Kind() == reflect.Ptr && !f.IsNil() &&
+			f.Type().Implements(nodeInterface) {
+			checkNodeText(t, name, f.Interface().(Node))
+		}
+	}
+}
+
+func TestParseRawFidelity(t *testing.T) {
+	input := "Paragraph ends with spaces.  \n"
+	tree, _ := Parse("raw", input)
+	exp := "Paragraph ends with spaces."
+	if p := tree.Nodes[0].(*ParagraphNode); p.Text != exp {
+		t.Errorf("Got: %q, Expect: %q", p.Text, exp)
+	}
+	tree, _ = Parse("raw", input, WithRawFidelity())
+	exp += "  "
+	if p := tree.Nodes[0].(*ParagraphNode); p.Text != exp {
+		t.Errorf("WithRawFidelity: Got: %q, Expect: %q", p.Text, exp)
+	}
+}
+
+func TestParseWithoutGoroutines(t *testing.T) {
+	// The lexer runs in the goroutine of the parser, so that parsing works
+	// in the single threaded scheduler of WebAssembly builds, and nothing
+	// is left running when the caller stops reading items.
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(1))
+	before := runtime.NumGoroutine()
+	l := lex("goroutines", "Title\n=====\n\nParagraph.\n")
+	if i := l.nextItem(); i == nil || i.Type != itemTitle {
+		t.Fatalf("Got: %v, Expect: itemTitle", i)
+	}
+	if n := runtime.NumGoroutine(); n != before {
+		t.Errorf("Goroutines while lexing: Got: %d, Expect: %d", n, before)
+	}
+	for _, p := range testPathsFromDirectory(corpus.Files) {
+		if _, err := fs.Stat(corpus.Files, p+"-nodes.json"); err != nil {
+			// Only the lexer is tested with the fixture
+			continue
+		}
+		data, err := corpus.Files.ReadFile(p + ".rst")
+		if err != nil {
+			t.Fatal(err)
+		}
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					t.Fatalf("%s: Parse panicked: %v", p, r)
+				}
+			}()
+			Parse(p, string(data[:len(data)-1]))
+		}()
+		if n := runtime.NumGoroutine(); n != before {
+			t.Errorf("%s: Goroutines after parsing: Got: %d, Expect: %d",
+				p, n, before)
+		}
 	}
 }
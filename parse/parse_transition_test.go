@@ -0,0 +1,112 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+// To enable debug output when testing, use "go test -debug"
+
+package parse
+
+import "testing"
+
+func TestParseTransitionAtStartBad0000(t *testing.T) {
+	// A transition at the start of the document.
+	testPath := testPathFromName("00.00-transition-at-start")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseTransitionAtEndBad0001(t *testing.T) {
+	// A transition at the end of the document.
+	testPath := testPathFromName("00.01-transition-at-end")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseTransitionAdjacentBad0002(t *testing.T) {
+	// Two transitions with no element between them.
+	testPath := testPathFromName("00.02-adjacent-transitions")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseTransitionBeginsSectionBad0003(t *testing.T) {
+	// A transition directly below a section title.
+	testPath := testPathFromName("00.03-transition-begins-section")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseTransitionAfterParagraphBad0004(t *testing.T) {
+	// An adornment line directly below a paragraph is a short underline,
+	// not a transition.
+	testPath := testPathFromName("00.04-transition-after-paragraph")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseTransitionAfterParagraphInListBad0005(t *testing.T) {
+	// An adornment line directly below a paragraph in a list item is an
+	// unexpected section title.
+	testPath := testPathFromName("00.05-transition-after-paragraph-in-list")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseTransitionBelowTitleBad0006(t *testing.T) {
+	// An adornment line directly below the underline of a title is a
+	// transition that begins the section.
+	testPath := testPathFromName("00.06-transition-below-title")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseTransitionBelowTitleAtEndBad0007(t *testing.T) {
+	// A transition directly below the underline of a title at the end of
+	// the input.
+	testPath := testPathFromName("00.07-transition-below-title-at-end")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseTransitionGood0000(t *testing.T) {
+	// A transition between two paragraphs.
+	testPath := testPathFromName("00.00-transition-between-paragraphs")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseTransitionInSectionGood0001(t *testing.T) {
+	// A transition of asterisks between two paragraphs of a section.
+	testPath := testPathFromName("00.01-transition-in-section")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseTransitionBeforeCommentGood0002(t *testing.T) {
+	// A transition followed by a comment does not end the document.
+	testPath := testPathFromName("00.02-transition-before-comment")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
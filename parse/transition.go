@@ -0,0 +1,46 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+// checkTransition checks the next node of l and reports it if it is a
+// transition that is not allowed where it is found, as the docutils
+// Transitions transform does. A transition may not begin the document or a
+// section, follow another transition, or end the document. atEnd is true if
+// nothing follows the node in the document. The transition is kept, it is
+// preceded by the system message reporting it, or followed by it if it ends
+// the document.
+func (t *Tree) checkTransition(l *bodyList, atEnd bool) {
+	tr, ok := (*l.nodes)[l.next].(*TransitionNode)
+	if ok && l.count == 0 {
+		t.transitionMessage(l, errorTransitionBeginsSection, tr)
+	} else if ok && l.afterTransition {
+		t.transitionMessage(l, errorAdjacentTransitions, tr)
+	}
+	l.next++
+	l.count++
+	l.afterTransition = ok
+	if ok && atEnd {
+		t.transitionMessage(l, errorTransitionEndsDocument, tr)
+	}
+}
+
+// transitionMessage inserts the system message err, reported at the line of
+// the transition tr, at the next node of l. The message is built from tr, the
+// items read by the parser may already follow it.
+func (t *Tree) transitionMessage(l *bodyList, err parserMessage,
+	tr *TransitionNode) {
+	m := newSystemMessage(&item{Type: itemSystemMessage, Line: tr.Line},
+		err, &t.id)
+	m.NodeList.append(newParagraph(&item{
+		Text:   err.Message(),
+		Length: len(err.Message()),
+	}, &t.id))
+	t.Messages.append(m)
+	nl := append(*l.nodes, nil)
+	copy(nl[l.next+1:], nl[l.next:])
+	nl[l.next] = m
+	*l.nodes = nl
+	l.next++
+}
@@ -0,0 +1,26 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+//go:build js && wasm
+
+package parse
+
+import "testing"
+
+func TestWasmParseSmoke(t *testing.T) {
+	// Fixtures of each kind of element, parsed from the fixtures embedded
+	// in the test binary.
+	for _, name := range []string{
+		"00.00-title-paragraph",
+		"00.00-different-bullets",
+		"00.00-directive-image",
+		"00.00-doctest-block",
+	} {
+		testPath := testPathFromName(name)
+		test := LoadParseTest(t, testPath)
+		pTree := parseTest(t, test)
+		eNodes := test.expectNodes()
+		checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+	}
+}
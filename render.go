@@ -0,0 +1,119 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package rst
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// renderFunc decodes generic options into the typed options of a writer and
+// writes doc to w.
+type renderFunc func(w io.Writer, doc *Document,
+	opts map[string]interface{}) error
+
+// writers are the output formats of Render.
+var writers = map[string]renderFunc{
+	"text": renderText,
+}
+
+// Render writes doc to w in format, using the writer registered for the
+// format. opts are the options of the writer, by the names used on the
+// command line. An unknown format, an unknown option, or an option value of
+// the wrong type is an error, and nothing is written.
+//
+// The formats and their options are:
+//
+//	text  WritePlainText
+//	      line-prefix (bool)    TextOptions.LinePrefix
+//	      newline (string)      TextOptions.Newline
+func Render(w io.Writer, doc *Document, format string,
+	opts map[string]interface{}) error {
+	render, ok := writers[format]
+	if !ok {
+		var names []string
+		for name := range writers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("unknown format %q; known formats are %s", format,
+			strings.Join(names, ", "))
+	}
+	return render(w, doc, opts)
+}
+
+func renderText(w io.Writer, doc *Document, opts map[string]interface{}) error {
+	var o TextOptions
+	err := decodeOptions("text", opts, func(d *optionDecoder) {
+		switch d.key {
+		case "line-prefix":
+			o.LinePrefix = d.bool()
+		case "newline":
+			o.Newline = d.string()
+		default:
+			d.unknown()
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return WritePlainText(w, doc, o)
+}
+
+// optionDecoder converts the value of a generic option, keeping the first
+// error.
+type optionDecoder struct {
+	format string
+	key    string
+	value  interface{}
+	err    error
+}
+
+// decodeOptions calls decode with each option of opts, in order by key, and
+// returns the first error.
+func decodeOptions(format string, opts map[string]interface{},
+	decode func(*optionDecoder)) error {
+	var keys []string
+	for key := range opts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	d := &optionDecoder{format: format}
+	for _, key := range keys {
+		d.key, d.value = key, opts[key]
+		decode(d)
+		if d.err != nil {
+			return d.err
+		}
+	}
+	return nil
+}
+
+func (d *optionDecoder) unknown() {
+	d.err = fmt.Errorf("unknown option %q for format %q", d.key, d.format)
+}
+
+func (d *optionDecoder) mistyped(expect string) {
+	d.err = fmt.Errorf("option %q for format %q must be a %s, not %T",
+		d.key, d.format, expect, d.value)
+}
+
+func (d *optionDecoder) bool() (b bool) {
+	b, ok := d.value.(bool)
+	if !ok {
+		d.mistyped("bool")
+	}
+	return
+}
+
+func (d *optionDecoder) string() (s string) {
+	s, ok := d.value.(string)
+	if !ok {
+		d.mistyped("string")
+	}
+	return
+}
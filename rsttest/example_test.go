@@ -0,0 +1,53 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package rsttest_test
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/demizer/go-rst/parse"
+	"github.com/demizer/go-rst/rsttest"
+)
+
+// writeOutline is the writer of another package. It writes the titles of the
+// sections of a parse tree, indented by their depth.
+func writeOutline(w io.Writer, nl parse.NodeList, depth int) {
+	for _, n := range nl {
+		if s, ok := n.(*parse.SectionNode); ok {
+			fmt.Fprintf(w, "%s%s\n", strings.Repeat("  ", depth),
+				s.Title.Text)
+			writeOutline(w, s.NodeList, depth+1)
+		}
+	}
+}
+
+func Example() {
+	// The writer is run on each document of the corpus. The parse tree
+	// is first checked against the tree expected by the go-rst tests, so
+	// that a change of the parser is found here and not taken for a bug
+	// of the writer.
+	for _, c := range rsttest.Corpus() {
+		if c.Nodes == nil {
+			continue
+		}
+		tree := c.Parse()
+		diffs, err := rsttest.Diff(c, tree.Nodes)
+		if err != nil || len(diffs) > 0 {
+			fmt.Printf("%s: unexpected parse tree\n", c.Path)
+			continue
+		}
+		writeOutline(io.Discard, tree.Nodes, 0)
+	}
+	c, _ := rsttest.Find("00.00-section-level-return")
+	writeOutline(os.Stdout, c.Parse().Nodes, 0)
+	// Output:
+	// Title 1
+	//   Title 2
+	//     Title 3
+	//   Title 4
+}
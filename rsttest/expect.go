@@ -0,0 +1,59 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package rsttest
+
+import (
+	"reflect"
+
+	"github.com/demizer/go-rst/internal/expect"
+	"github.com/demizer/go-rst/parse"
+)
+
+// expectTypes are the node types given to expect.Decode to decode the
+// expected nodes of the corpus.
+var expectTypes = func() expect.Types {
+	types := make(expect.Types, len(nodeStructs))
+	for t, s := range nodeStructs {
+		types[t.String()] = s
+	}
+	return types
+}()
+
+// nodeStructs maps each parse.NodeType to the struct used by the parser for
+// nodes of that type.
+var nodeStructs = map[parse.NodeType]reflect.Type{
+	parse.NodeSection:            reflect.TypeOf(parse.SectionNode{}),
+	parse.NodeParagraph:          reflect.TypeOf(parse.ParagraphNode{}),
+	parse.NodeAdornment:          reflect.TypeOf(parse.AdornmentNode{}),
+	parse.NodeBlockQuote:         reflect.TypeOf(parse.BlockQuoteNode{}),
+	parse.NodeSystemMessage:      reflect.TypeOf(parse.SystemMessageNode{}),
+	parse.NodeLiteralBlock:       reflect.TypeOf(parse.LiteralBlockNode{}),
+	parse.NodeTransition:         reflect.TypeOf(parse.TransitionNode{}),
+	parse.NodeTitle:              reflect.TypeOf(parse.TitleNode{}),
+	parse.NodeComment:            reflect.TypeOf(parse.CommentNode{}),
+	parse.NodeBulletList:         reflect.TypeOf(parse.BulletListNode{}),
+	parse.NodeBulletListItem:     reflect.TypeOf(parse.BulletListItemNode{}),
+	parse.NodeEnumList:           reflect.TypeOf(parse.EnumListNode{}),
+	parse.NodeDefinitionList:     reflect.TypeOf(parse.DefinitionListNode{}),
+	parse.NodeDefinitionListItem: reflect.TypeOf(parse.DefinitionListItemNode{}),
+	parse.NodeDefinitionTerm:     reflect.TypeOf(parse.DefinitionTermNode{}),
+	parse.NodeDefinition:         reflect.TypeOf(parse.DefinitionNode{}),
+	parse.NodeAttribution:        reflect.TypeOf(parse.AttributionNode{}),
+	parse.NodeEnumListItem:       reflect.TypeOf(parse.EnumListItemNode{}),
+	parse.NodeClassifier:         reflect.TypeOf(parse.ClassifierNode{}),
+	parse.NodeFieldList:          reflect.TypeOf(parse.FieldListNode{}),
+	parse.NodeField:              reflect.TypeOf(parse.FieldNode{}),
+	parse.NodeFieldName:          reflect.TypeOf(parse.FieldNameNode{}),
+	parse.NodeFieldBody:          reflect.TypeOf(parse.FieldBodyNode{}),
+	parse.NodeDocinfo:            reflect.TypeOf(parse.DocinfoNode{}),
+	parse.NodeBibliographicField: reflect.TypeOf(parse.BibliographicFieldNode{}),
+	parse.NodeOptionList:         reflect.TypeOf(parse.OptionListNode{}),
+	parse.NodeOptionListItem:     reflect.TypeOf(parse.OptionListItemNode{}),
+	parse.NodeOption:             reflect.TypeOf(parse.OptionNode{}),
+	parse.NodeOptionArgument:     reflect.TypeOf(parse.OptionArgumentNode{}),
+	parse.NodeDescription:        reflect.TypeOf(parse.DescriptionNode{}),
+	parse.NodeDoctestBlock:       reflect.TypeOf(parse.DoctestBlockNode{}),
+	parse.NodeCodeBlock:          reflect.TypeOf(parse.CodeBlockNode{}),
+}
@@ -0,0 +1,222 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+// Package rsttest provides the test corpus of go-rst: the reStructuredText
+// documents used by the go-rst tests, with the lexer items and the parse tree
+// expected for each. Other packages, such as alternative writers, can use the
+// corpus to check themselves against the same documents and parse trees. The
+// corpus is embedded in the package, so it does not depend on the directory
+// the tests are run from.
+package rsttest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/demizer/go-rst/internal/corpus"
+	"github.com/demizer/go-rst/internal/expect"
+	"github.com/demizer/go-rst/parse"
+)
+
+// Case is a document of the corpus.
+type Case struct {
+	// Name is the base name of the document, such as
+	// "00.00-title-paragraph". Names are unique within the corpus.
+	Name string
+
+	// Path locates the document in the corpus, such as
+	// "test-section/00-title-good/00.00-title-paragraph", the path of its
+	// .rst file without the extension.
+	Path string
+
+	// Input is the text of the document, without the final newline of the
+	// file.
+	Input string
+
+	// Items is the JSON list of the items expected from the lexer, or nil
+	// if there is none. The lexer is internal to the parse package, the
+	// items are given for reference.
+	Items []byte
+
+	// Nodes is the JSON list of the nodes expected from the parser, or nil
+	// if the parsing of the document is not tested. Diff compares them to
+	// a parse tree.
+	Nodes []byte
+
+	// Options are the options the document is parsed with to give Nodes,
+	// given by the -options.json file of the document.
+	Options []parse.ParseOption
+}
+
+// options is the -options.json file of a document parsed with options other
+// than the defaults.
+type options struct {
+	NBSPIndent           bool   `json:"nbspIndent"`
+	FrontMatterComment   bool   `json:"frontMatterComment"`
+	Docinfo              bool   `json:"docinfo"`
+	BidiControlsRejected bool   `json:"bidiControlsRejected"`
+	MaxSectionDepth      int    `json:"maxSectionDepth"`
+	DepthMode            string `json:"depthMode"` // "strict" or "flatten"
+}
+
+// depthModes are the DepthModes named by the -options.json files.
+var depthModes = map[string]parse.DepthMode{
+	"strict":  parse.DepthStrict,
+	"flatten": parse.DepthFlatten,
+}
+
+// parseOptions returns the parse options given by data, the -options.json file
+// of a document.
+func parseOptions(data []byte) (opts []parse.ParseOption, err error) {
+	var o options
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.DisallowUnknownFields()
+	if err := d.Decode(&o); err != nil {
+		return nil, err
+	}
+	if o.NBSPIndent {
+		opts = append(opts, parse.WithNBSPIndent())
+	}
+	if o.FrontMatterComment {
+		opts = append(opts, parse.WithFrontMatterComment())
+	}
+	if o.Docinfo {
+		opts = append(opts, parse.WithDocinfo())
+	}
+	if o.BidiControlsRejected {
+		opts = append(opts, parse.WithBidiControlsRejected())
+	}
+	if o.MaxSectionDepth > 0 {
+		mode, ok := depthModes[o.DepthMode]
+		if !ok {
+			return nil, fmt.Errorf("unknown depthMode %q",
+				o.DepthMode)
+		}
+		opts = append(opts, parse.WithMaxSectionDepth(o.MaxSectionDepth,
+			mode))
+	}
+	return
+}
+
+// nodesTested returns true if the expected nodes data of the document named
+// name are tested. The nodes of the documents numbered "xx.xx" are not, nor
+// the nodes that are still in the docutils pseudo XML form, as the parser does
+// not give them yet.
+func nodesTested(name string, data []byte) bool {
+	return !strings.HasPrefix(name, "xx.xx") &&
+		!bytes.HasPrefix(bytes.TrimSpace(data), []byte("<"))
+}
+
+var (
+	casesOnce sync.Once
+	cases     []Case
+)
+
+// Corpus returns the documents of the corpus, sorted by path. The slice is a
+// copy, it can be changed by the caller.
+func Corpus() []Case {
+	casesOnce.Do(func() { cases = loadCorpus(corpus.Files) })
+	return append([]Case(nil), cases...)
+}
+
+// loadCorpus returns the documents of fsys. Each document is a .rst file,
+// with its expected items and nodes in the -items.json and -nodes.json files
+// of the same name.
+func loadCorpus(fsys fs.FS) (cases []Case) {
+	paths, err := fs.Glob(fsys, "*/*/*.rst")
+	if err != nil {
+		panic(err)
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		input, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			panic(err)
+		}
+		base := strings.TrimSuffix(p, ".rst")
+		c := Case{
+			Name:  path.Base(base),
+			Path:  base,
+			Input: strings.TrimSuffix(string(input), "\n"),
+		}
+		// A missing file leaves the expected output nil.
+		c.Items, _ = fs.ReadFile(fsys, base+"-items.json")
+		c.Nodes, _ = fs.ReadFile(fsys, base+"-nodes.json")
+		if !nodesTested(c.Name, c.Nodes) {
+			c.Nodes = nil
+		}
+		data, err := fs.ReadFile(fsys, base+"-options.json")
+		if err == nil {
+			if c.Options, err = parseOptions(data); err != nil {
+				panic(fmt.Errorf("%s-options.json: %s", base,
+					err))
+			}
+		}
+		cases = append(cases, c)
+	}
+	return
+}
+
+// Find returns the document of the corpus named name, such as
+// "00.00-title-paragraph". ok is false if there is none.
+func Find(name string) (c Case, ok bool) {
+	for _, c := range Corpus() {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return
+}
+
+// Parse parses the input of c with the options of c.
+func (c Case) Parse() *parse.Tree {
+	tree, _ := parse.Parse(c.Name, c.Input, c.Options...)
+	return tree
+}
+
+// Diff compares nodes to the nodes expected for c, using parse.DiffNodeLists,
+// and returns the differences found. The start positions that are not given
+// by the nodes of c match a node at position zero or one. An error is returned
+// if the nodes of c cannot be decoded.
+func Diff(c Case, nodes parse.NodeList) (diffs []parse.NodeDiff, err error) {
+	var eTree []interface{}
+	if err := json.Unmarshal(c.Nodes, &eTree); err != nil {
+		return nil, err
+	}
+	var eNodes parse.NodeList
+	if err := expect.Decode(eTree, &eNodes, expectTypes); err != nil {
+		return nil, err
+	}
+	for _, d := range parse.DiffNodeLists(eNodes, nodes) {
+		if !expect.Omitted(d.Field, d.A, d.B) {
+			diffs = append(diffs, d)
+		}
+	}
+	return
+}
+
+// CheckNodes reports an error to t for each difference between nodes and the
+// nodes expected for c. The test is ended if the nodes expected for c cannot
+// be decoded, or c has none.
+func CheckNodes(t testing.TB, c Case, nodes parse.NodeList) {
+	t.Helper()
+	if c.Nodes == nil {
+		t.Fatalf("%s: no expected nodes", c.Path)
+	}
+	diffs, err := Diff(c, nodes)
+	if err != nil {
+		t.Fatalf("%s-nodes.json: %s", c.Path, err)
+	}
+	for _, d := range diffs {
+		t.Errorf("%s: %s.%s\n\t    Got: %#v\n\t Expect: %#v", c.Path,
+			d.Path, d.Field, d.B, d.A)
+	}
+}
@@ -0,0 +1,20 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package rsttest
+
+import "testing"
+
+func TestCorpusNodes(t *testing.T) {
+	// Each document parses to its expected nodes.
+	for _, c := range Corpus() {
+		if c.Nodes == nil {
+			continue
+		}
+		c := c
+		t.Run(c.Path, func(t *testing.T) {
+			CheckNodes(t, c, c.Parse().Nodes)
+		})
+	}
+}
@@ -0,0 +1,127 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package rst
+
+import (
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/demizer/go-rst/parse"
+)
+
+// Counts are the sizes of the content of a document or section.
+type Counts struct {
+	// Words is the number of words in the text written by WritePlainText.
+	// Words are counted by countWords.
+	Words int `json:"words"`
+
+	// LiteralLines is the number of lines in literal blocks, not counting
+	// blank lines.
+	LiteralLines int `json:"literalLines"`
+}
+
+// ReadingTime returns the time needed to read the words at wordsPerMinute.
+// Literal blocks are not included. ReadingTime returns zero if
+// wordsPerMinute is not positive.
+func (c Counts) ReadingTime(wordsPerMinute int) time.Duration {
+	if wordsPerMinute <= 0 {
+		return 0
+	}
+	return time.Duration(c.Words) * time.Minute /
+		time.Duration(wordsPerMinute)
+}
+
+func (c *Counts) add(o Counts) {
+	c.Words += o.Words
+	c.LiteralLines += o.LiteralLines
+}
+
+// Stats are the counts of a whole document and of each of its sections.
+type Stats struct {
+	Counts
+	Sections []SectionStats `json:"sections,omitempty"`
+}
+
+// SectionStats are the counts of a section. The counts include the title and
+// the subsections of the section.
+type SectionStats struct {
+	Title      string `json:"title"`
+	Level      int    `json:"level"`
+	parse.Line `json:"line"`
+	Counts
+	Sections []SectionStats `json:"sections,omitempty"`
+}
+
+// Stats returns the word and literal block line counts of the document and of
+// each section.
+func (d *Document) Stats() (s Stats) {
+	if d.Tree == nil {
+		return
+	}
+	s.Counts, s.Sections = nodeStats(d.Nodes)
+	return
+}
+
+// nodeStats returns the counts of the nodes in nl and the stats of the
+// sections in nl.
+func nodeStats(nl parse.NodeList) (c Counts, sections []SectionStats) {
+	tw := &textWalker{
+		block: func(line parse.Line, words []string) {
+			c.Words += countWords(words)
+		},
+		literal: func(b *parse.LiteralBlockNode) {
+			c.LiteralLines += literalLines(b)
+		},
+	}
+	tw.section = func(n *parse.SectionNode) {
+		s := SectionStats{Title: n.Title.Text, Level: n.Level,
+			Line: n.Title.Line}
+		s.Words = countWords(strings.Fields(n.Title.Text))
+		sub, subSections := nodeStats(n.NodeList)
+		s.add(sub)
+		s.Sections = subSections
+		c.add(s.Counts)
+		sections = append(sections, s)
+	}
+	tw.nodes(nl)
+	return
+}
+
+// countWords returns the number of words in fields, which are strings
+// without spaces. A word is a run of letters and digits, so punctuation is
+// not counted and "don't" is one word. Han ideographs, Hiragana, and Katakana
+// are written without spaces between words. Segmenting those scripts needs a
+// dictionary, so, as an approximation, each of their runes counts as a word.
+func countWords(fields []string) (n int) {
+	for _, f := range fields {
+		inWord := false
+		for _, r := range f {
+			switch {
+			case unicode.In(r, unicode.Han, unicode.Hiragana,
+				unicode.Katakana):
+				n++
+				inWord = false
+			case unicode.IsLetter(r) || unicode.IsDigit(r):
+				if !inWord {
+					n++
+				}
+				inWord = true
+			}
+		}
+	}
+	return
+}
+
+// literalLines returns the number of lines that are not blank in the literal
+// block b.
+func literalLines(b *parse.LiteralBlockNode) (n int) {
+	for _, line := range strings.Split(b.Text, "\n") {
+		if strings.TrimSpace(line) != "" {
+			n++
+		}
+	}
+	return
+}
@@ -0,0 +1,163 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package rst
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/demizer/go-rst/parse"
+)
+
+// TextOptions controls the output of WritePlainText.
+type TextOptions struct {
+	// LinePrefix prefixes each line of output with "name:line:", where name
+	// is the name of the Document and line is the line of the block in the
+	// input, so that tools can map their findings back to the source.
+	LinePrefix bool
+
+	// Newline ends each line of output. It must be LF, CRLF, or empty for
+	// LF.
+	Newline string
+}
+
+// uriSchemes are the prefixes of the standalone URIs removed from the text.
+var uriSchemes = []string{"http://", "https://", "ftp://", "mailto:"}
+
+// WritePlainText writes the natural language content of doc to w, such as
+// paragraphs, section titles, list items, definitions, fields, and option
+// descriptions, for use with spell checkers and other tools that need prose
+// only. Each block is written on one line. Literal blocks, doctest blocks,
+// option strings, comments, system messages, section adornments, and
+// standalone URIs are not written.
+func WritePlainText(w io.Writer, doc *Document, opts TextOptions) error {
+	lw, err := newLineWriter(w, opts.Newline)
+	if err != nil || doc.Tree == nil {
+		return err
+	}
+	tw := &textWriter{w: lw, name: doc.name, opts: opts}
+	(&textWalker{block: tw.block}).nodes(doc.Nodes)
+	return lw.flush()
+}
+
+type textWriter struct {
+	w    *lineWriter
+	name string
+	opts TextOptions
+}
+
+// block writes the words of a block, found at line of the input, as one line.
+func (tw *textWriter) block(line parse.Line, words []string) {
+	if tw.opts.LinePrefix {
+		tw.w.write(fmt.Sprintf("%s:%d:", tw.name, line))
+	}
+	tw.w.line(strings.Join(words, " "))
+}
+
+// textWalker walks a parse tree and calls its functions with the natural
+// language content written by WritePlainText, and with the comments.
+type textWalker struct {
+	// block, if not nil, is called with the line and words of each block
+	// of text.
+	block func(line parse.Line, words []string)
+
+	// comment, if not nil, is called with each comment.
+	comment func(*parse.CommentNode)
+
+	// literal, if not nil, is called with each literal block.
+	literal func(*parse.LiteralBlockNode)
+
+	// section, if not nil, is called with each section instead of walking
+	// the title and contents of the section.
+	section func(*parse.SectionNode)
+}
+
+// text calls block with the words of text, found at line of the input.
+// Standalone URIs are not words.
+func (tw *textWalker) text(line parse.Line, text string) {
+	if tw.block == nil {
+		return
+	}
+	var words []string
+	for _, word := range strings.Fields(text) {
+		if !isStandaloneURI(word) {
+			words = append(words, word)
+		}
+	}
+	if len(words) > 0 {
+		tw.block(line, words)
+	}
+}
+
+// nodes walks the nodes in nl.
+func (tw *textWalker) nodes(nl parse.NodeList) {
+	for _, n := range nl {
+		switch n := n.(type) {
+		case *parse.SectionNode:
+			if tw.section != nil {
+				tw.section(n)
+				break
+			}
+			tw.text(n.Title.Line, n.Title.Text)
+			tw.nodes(n.NodeList)
+		case *parse.ParagraphNode:
+			tw.text(n.Line, n.Text)
+		case *parse.LiteralBlockNode:
+			if tw.literal != nil {
+				tw.literal(n)
+			}
+		case *parse.CommentNode:
+			if tw.comment != nil {
+				tw.comment(n)
+			}
+		case *parse.AttributionNode:
+			tw.text(n.Line, n.Text)
+		case *parse.BlockQuoteNode:
+			tw.nodes(n.NodeList)
+		case *parse.BulletListNode:
+			tw.nodes(n.NodeList)
+		case *parse.BulletListItemNode:
+			tw.nodes(n.NodeList)
+		case *parse.EnumListNode:
+			tw.nodes(n.NodeList)
+		case *parse.EnumListItemNode:
+			tw.nodes(n.NodeList)
+		case *parse.DefinitionListNode:
+			tw.nodes(n.NodeList)
+		case *parse.DefinitionListItemNode:
+			tw.text(n.Term.Line, n.Term.Text)
+			if n.Definition != nil {
+				tw.nodes(n.Definition.NodeList)
+			}
+		case *parse.DefinitionNode:
+			tw.nodes(n.NodeList)
+		case *parse.FieldListNode:
+			tw.nodes(n.NodeList)
+		case *parse.FieldNode:
+			tw.text(n.Name.Line, n.Name.Text)
+			tw.nodes(n.Body.NodeList)
+		case *parse.DocinfoNode:
+			tw.nodes(n.NodeList)
+		case *parse.BibliographicFieldNode:
+			tw.text(n.Name.Line, n.Name.Text)
+			tw.nodes(n.Body.NodeList)
+		case *parse.OptionListNode:
+			tw.nodes(n.NodeList)
+		case *parse.OptionListItemNode:
+			// The options, such as "-f FILE", are not prose.
+			tw.nodes(n.Description.NodeList)
+		}
+	}
+}
+
+func isStandaloneURI(word string) bool {
+	for _, s := range uriSchemes {
+		if strings.HasPrefix(word, s) && len(word) > len(s) {
+			return true
+		}
+	}
+	return false
+}
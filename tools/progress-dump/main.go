@@ -76,7 +76,7 @@ func (t *Table) Dump() {
 	// 3 == 2x space and "+"
 	tWidth := 1 + t.MaxCol1Chars + 3 + t.MaxCol2Chars + 3 + t.MaxCol3Chars + 1
 	tTop := strings.Repeat("-", tWidth)
-	topWithEndPoints := fmt.Sprintf("+" + tTop + "+")
+	topWithEndPoints := "+" + tTop + "+"
 
 	fakeHdr := fmt.Sprintf("| %s | %s | %s |", "**Done**",
 		// 8 == (4x asterisks, 2 spaces, 2 frame ascii)
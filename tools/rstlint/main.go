@@ -0,0 +1,140 @@
+// rstlint -- Checks reStructuredText files against style rules
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+// rstlint parses reStructuredText files and reports the problems found by
+// the style rules of the lint package that are enabled with options. The
+// system messages of the parser of the level given with --report-level and
+// above, and invisible control characters, which can make text display
+// differently than it is written, are always reported. Each problem is followed by the line of
+// input it is found on, with a caret below the column of the problem, unless
+// --no-source-echo is given. The exit status is 1 if any problems are found,
+// and 2 if a file cannot be read.
+//
+// With --stats, rstlint writes the statistics of each file as a line of JSON
+// instead, as returned by Document.Stats. With --extract-text, it writes the
+// prose of each file instead, as rendered by rst.Render in the text format,
+// each line prefixed with the file name and the line of input.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+
+	"github.com/demizer/go-rst"
+	"github.com/demizer/go-rst/lint"
+	"github.com/demizer/go-rst/parse"
+	"github.com/docopt/docopt-go"
+)
+
+var usage = `rstlint - Checks reStructuredText files against style rules
+
+Usage:
+  rstlint [options] <FILE>...
+  rstlint -h | --help
+
+Options:
+  -h --help                Show the help message.
+  --report-level <LEVEL>   Report the system messages of the parser of LEVEL
+                           and above, one of info, warning, error, and severe,
+                           or 1-4 [default: warning].
+  --adornments <CHARS>     Require section level n to be adorned with the nth
+                           rune of CHARS, for example "#*=-^".
+  --overline-levels <N>    The number of section levels that are adorned with
+                           an overline [default: 0].
+  --max-line-length <N>    Report lines longer than N characters, except in
+                           literal blocks and grid tables.
+  --require-annotation <KEY>
+                           Report files without a comment annotation with
+                           the key KEY, such as ".. KEY: value".
+  --no-source-echo         Do not write the line of input below each problem.
+  --stats                  Write the word and literal line counts of each
+                           file as JSON instead of checking it.
+  --extract-text           Write the prose of each file, for spell checkers,
+                           instead of checking it.
+`
+
+// fileStats is the JSON written with --stats.
+type fileStats struct {
+	File string `json:"file"`
+	rst.Stats
+}
+
+// rules returns the rules enabled by the options in args.
+func rules(args map[string]interface{}) (r []lint.Rule, err error) {
+	var lvl parse.SystemMessageLevel
+	level := args["--report-level"].(string)
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("invalid --report-level: %s", err)
+	}
+	r = append(r, lint.SystemMessages(lvl), lint.InvisibleControls())
+	if chars, ok := args["--adornments"].(string); ok {
+		n, err := strconv.Atoi(args["--overline-levels"].(string))
+		if err != nil {
+			return nil, fmt.Errorf("invalid --overline-levels: %s", err)
+		}
+		r = append(r, lint.AdornmentSequence(chars, n))
+	}
+	if max, ok := args["--max-line-length"].(string); ok {
+		n, err := strconv.Atoi(max)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid --max-line-length %q", max)
+		}
+		r = append(r, lint.MaxLineLength(n))
+	}
+	if key, ok := args["--require-annotation"].(string); ok {
+		r = append(r, lint.RequireAnnotation(key))
+	}
+	return
+}
+
+func main() {
+	args, err := docopt.Parse(usage, nil, true, "rstlint", false)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	r, err := rules(args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	status := 0
+	enc := json.NewEncoder(os.Stdout)
+	for _, path := range args["<FILE>"].([]string) {
+		text, err := ioutil.ReadFile(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		doc, _ := rst.New(path).Parse(string(text))
+		if args["--stats"].(bool) {
+			if err := enc.Encode(fileStats{path, doc.Stats()}); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(2)
+			}
+			continue
+		}
+		if args["--extract-text"].(bool) {
+			err := rst.Render(os.Stdout, doc, "text",
+				map[string]interface{}{"line-prefix": true})
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(2)
+			}
+			continue
+		}
+		for _, m := range lint.Check(doc, r...) {
+			text := m.String()
+			if !args["--no-source-echo"].(bool) {
+				text = lint.FormatMessageWithSource(m, doc.Source())
+			}
+			fmt.Printf("%s:%s\n", path, text)
+			status = 1
+		}
+	}
+	os.Exit(status)
+}
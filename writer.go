@@ -0,0 +1,63 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package rst
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// The line endings accepted by the writers.
+const (
+	LF   = "\n"
+	CRLF = "\r\n"
+)
+
+// lineWriter writes the output of a writer line by line, ending each line
+// with the configured newline. Writers use it instead of writing newlines
+// themselves. The first error is kept: later writes do nothing, and flush
+// returns the error.
+type lineWriter struct {
+	w       *bufio.Writer
+	newline string
+	err     error
+}
+
+// newLineWriter returns a lineWriter writing to w. newline must be LF, CRLF,
+// or empty for LF.
+func newLineWriter(w io.Writer, newline string) (*lineWriter, error) {
+	switch newline {
+	case "":
+		newline = LF
+	case LF, CRLF:
+	default:
+		return nil, fmt.Errorf("invalid newline %q; valid newlines are %q "+
+			"and %q", newline, LF, CRLF)
+	}
+	return &lineWriter{w: bufio.NewWriter(w), newline: newline}, nil
+}
+
+// write writes text without ending the line. text must not contain
+// newlines.
+func (lw *lineWriter) write(text string) {
+	if lw.err == nil {
+		_, lw.err = lw.w.WriteString(text)
+	}
+}
+
+// line writes text and ends the line.
+func (lw *lineWriter) line(text string) {
+	lw.write(text)
+	lw.write(lw.newline)
+}
+
+// flush writes any buffered output and returns the first error encountered.
+func (lw *lineWriter) flush() error {
+	if lw.err == nil {
+		lw.err = lw.w.Flush()
+	}
+	return lw.err
+}